@@ -5,6 +5,7 @@ go 1.12
 require (
 	github.com/mwf/golidays v0.0.0-20171220112810-4e929a3d87fb
 	github.com/sirupsen/logrus v1.4.1
+	go.etcd.io/bbolt v1.3.6
 )
 
 replace github.com/mwf/golidays => ../.
@@ -1,14 +1,31 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"time"
 
 	"github.com/mwf/golidays/crawler"
+	"github.com/mwf/golidays/model"
 	"github.com/mwf/golidays/service"
+	"github.com/mwf/golidays/service/backuper"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/bolt"
+	"github.com/mwf/golidays/service/store/history"
 	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/migrate"
 	"github.com/sirupsen/logrus"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	dbPath          = flag.String("db", "", "bolt database file to keep holidays, in-memory storage is used if empty")
+	migrateFrom     = flag.String("migrate-from", "", "backup file (*.yml) or bolt database to migrate the calendar from to -db, exits after the migration")
+	migrateCalendar = flag.String("migrate-calendar", model.CalendarRU.String(), "calendar to migrate with -migrate-from")
+	historyDir      = flag.String("history-dir", "", "directory of calendar version logs, enables version history if set")
 )
 
 func waitInterrupt() {
@@ -21,15 +38,132 @@ func waitInterrupt() {
 	}
 }
 
+// newStorage returns a storage of the calendar: bolt one if the database is
+// opened, in-memory otherwise
+func newStorage(db *bbolt.DB, calendar model.Calendar) (store.Store, error) {
+	if db == nil {
+		return memory.New(), nil
+	}
+	return bolt.New(db, fmt.Sprintf("holidays-%s", calendar))
+}
+
+// newHistoryLog returns the version log of the calendar in -history-dir
+func newHistoryLog(calendar model.Calendar) history.Log {
+	return history.NewFileLog(filepath.Join(*historyDir, fmt.Sprintf("history-%s.jsonl", calendar)))
+}
+
+// migrateStorage copies the calendar from the backup file or the bolt
+// database to the storage of the opened database
+func migrateStorage(db *bbolt.DB, from string, calendar model.Calendar, logger *logrus.Logger) error {
+	dst, err := newStorage(db, calendar)
+	if err != nil {
+		return err
+	}
+
+	var src store.Store
+	if ext := filepath.Ext(from); ext == ".yml" || ext == ".yaml" {
+		holidays, err := backuper.ReadFile(from)
+		if err != nil {
+			return err
+		}
+		src = memory.New()
+		if err := src.Restore(holidays); err != nil {
+			return err
+		}
+	} else {
+		srcDB, err := bbolt.Open(from, 0600, &bbolt.Options{Timeout: time.Second})
+		if err != nil {
+			return fmt.Errorf("error opening source database: %s", err)
+		}
+		defer srcDB.Close()
+
+		if src, err = newStorage(srcDB, calendar); err != nil {
+			return err
+		}
+	}
+
+	report, err := migrate.Copy(dst, src)
+	for _, h := range report.Missing {
+		logger.Warnf("missing holiday: %s %s", h.Date, h.Type)
+	}
+	for _, h := range report.Extra {
+		logger.Warnf("extra holiday: %s %s", h.Date, h.Type)
+	}
+	for _, c := range report.Different {
+		logger.Warnf("different holiday: %#v != source %#v", c.New, c.Old)
+	}
+	if len(report.MissingYears) > 0 || len(report.ExtraYears) > 0 {
+		logger.Warnf("missing years: %v, extra years: %v", report.MissingYears, report.ExtraYears)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("calendar %s migrated from '%s': %d holidays", calendar, from, report.Holidays)
+	return nil
+}
+
 func main() {
+	flag.Parse()
+
 	c := crawler.NewConsultantRu()
-	storage := memory.New()
 	logger := logrus.New()
 	logger.Level = logrus.DebugLevel
 	logger.Formatter = &logrus.TextFormatter{
 		FullTimestamp: true,
 	}
 
+	var db *bbolt.DB
+	if *dbPath != "" {
+		var err error
+		db, err = bbolt.Open(*dbPath, 0600, &bbolt.Options{Timeout: time.Second})
+		if err != nil {
+			logger.Warnf("error opening database: %s", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+	}
+
+	if *migrateFrom != "" {
+		if db == nil {
+			logger.Warnf("-db is required for migration")
+			os.Exit(1)
+		}
+		calendar, err := model.ParseCalendar(*migrateCalendar)
+		if err != nil {
+			logger.Warnf("error parsing -migrate-calendar: %s", err)
+			os.Exit(1)
+		}
+		if err := migrateStorage(db, *migrateFrom, calendar, logger); err != nil {
+			logger.Warnf("error migrating storage: %s", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	storage, err := newStorage(db, model.CalendarRU)
+	if err != nil {
+		logger.Warnf("error initializing storage: %s", err)
+		os.Exit(1)
+	}
+
+	calendars := make(map[model.Calendar]service.CalendarConfig)
+	for calendar, holidays := range model.RegionalPublicHolidays {
+		calStorage, err := newStorage(db, calendar)
+		if err != nil {
+			logger.Warnf("error initializing storage: %s", err)
+			os.Exit(1)
+		}
+		calConfig := service.CalendarConfig{
+			Crawler: crawler.NewRegional(c, holidays),
+			Storage: calStorage,
+		}
+		if *historyDir != "" {
+			calConfig.HistoryLog = newHistoryLog(calendar)
+		}
+		calendars[calendar] = calConfig
+	}
+
 	config := &service.Config{
 		Updater: service.UpdaterConfig{
 			Crawler: c,
@@ -39,8 +173,17 @@ func main() {
 			Period:   1 * time.Minute,
 			BasePath: "./var",
 		},
-		Storage: storage,
-		Logger:  logger,
+		Storage:   storage,
+		Logger:    logger,
+		Calendars: calendars,
+	}
+	if *historyDir != "" {
+		if err := os.MkdirAll(*historyDir, 0755); err != nil {
+			logger.Warnf("error creating history directory: %s", err)
+			os.Exit(1)
+		}
+		config.History = true
+		config.HistoryLog = newHistoryLog(model.CalendarRU)
 	}
 
 	srv, err := service.New(config)
@@ -48,8 +191,11 @@ func main() {
 		logger.Warnf("error initializing service: %s", err)
 		os.Exit(1)
 	}
-	if err := srv.RestoreStorage(); err != nil {
-		logger.Warnf("error restoring storage: %s", err)
+	// persistent storage survives restarts, backups are needed for the first run
+	if len(storage.Years()) == 0 {
+		if err := srv.RestoreStorage(); err != nil {
+			logger.Warnf("error restoring storage: %s", err)
+		}
 	}
 	srv.Run()
 
@@ -41,11 +41,48 @@ func NewConsultantRu() *ConsultantRu {
 }
 
 func (c *ConsultantRu) ScrapeYear(year int) (model.Holidays, error) {
-	doc, err := goquery.NewDocument(fmt.Sprintf(yearURL, year))
+	doc, err := c.fetchYear(year)
 	if err != nil {
 		return nil, err
 	}
 
+	return c.parseYear(doc, year)
+}
+
+// ScrapeSummary returns the summary table for the year, which is published
+// next to the month tables.
+func (c *ConsultantRu) ScrapeSummary(year int) (model.Summaries, error) {
+	doc, err := c.fetchYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseSummary(doc, year)
+}
+
+func (c *ConsultantRu) fetchYear(year int) (*goquery.Document, error) {
+	return goquery.NewDocument(fmt.Sprintf(yearURL, year))
+}
+
+// parseYear parses holidays and checks them against the summary table
+func (c *ConsultantRu) parseYear(doc *goquery.Document, year int) (model.Holidays, error) {
+	holidays, err := c.parseHolidays(doc, year)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := c.parseSummary(doc, year)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSummaries(holidays, summaries); err != nil {
+		return nil, err
+	}
+
+	return holidays, nil
+}
+
+func (c *ConsultantRu) parseHolidays(doc *goquery.Document, year int) (model.Holidays, error) {
 	months, err := c.getMonthTablesOrdered(doc)
 	if err != nil {
 		return nil, err
@@ -58,31 +95,18 @@ func (c *ConsultantRu) ScrapeYear(year int) (model.Holidays, error) {
 
 		var monthError error
 		month.Find("td").Each(func(i int, s *goquery.Selection) {
-			if !s.HasClass("weekend") && !s.HasClass("preholiday") {
+			if monthError != nil {
 				return
 			}
 
-			dayS := strings.TrimSuffix(s.Text(), "*")
-			day, err := strconv.ParseInt(dayS, 10, 32)
+			holiday, ok, err := c.parseDay(s, year, time.Month(monthN))
 			if err != nil {
-				monthError = fmt.Errorf("can't parse day from '%s' for month %d", dayS, monthN)
+				monthError = err
 				return
 			}
-
-			holiday := model.Holiday{
-				Date: model.NewDay(year, time.Month(monthN), int(day)),
+			if ok {
+				holidays = append(holidays, holiday)
 			}
-			switch {
-			case s.HasClass("weekend"):
-				if holiday.Date.Weekday() == time.Saturday || holiday.Date.Weekday() == time.Sunday {
-					holiday.Type = model.TypeWeekend
-				} else {
-					holiday.Type = model.TypeHoliday
-				}
-			case s.HasClass("preholiday"):
-				holiday.Type = model.TypePreholiday
-			}
-			holidays = append(holidays, holiday)
 		})
 
 		if monthError != nil {
@@ -90,11 +114,57 @@ func (c *ConsultantRu) ScrapeYear(year int) (model.Holidays, error) {
 		}
 	}
 
+	applyNotes(holidays, c.parseNotes(doc, year))
+
 	// The date should be already sorted, but let's sort it for sure.
 	sort.Sort(model.HolidaysByDate(holidays))
 	return holidays, nil
 }
 
+// parseDay parses a month table cell. Returns false if the day is an ordinary
+// working day or the cell does not belong to the month.
+func (c *ConsultantRu) parseDay(s *goquery.Selection, year int, month time.Month) (model.Holiday, bool, error) {
+	dayS := strings.TrimSuffix(strings.TrimSpace(s.Text()), "*")
+	if s.HasClass("inactively") || dayS == "" {
+		return model.Holiday{}, false, nil
+	}
+
+	day, err := strconv.ParseInt(dayS, 10, 32)
+	if err != nil {
+		return model.Holiday{}, false, fmt.Errorf("can't parse day from '%s' for month %d", dayS, month)
+	}
+
+	holiday := model.Holiday{
+		Date: model.NewDate(year, month, int(day)),
+	}
+	weekend := holiday.Date.Weekday() == time.Saturday || holiday.Date.Weekday() == time.Sunday
+	switch {
+	case s.HasClass("weekend"):
+		_, public := model.FindPublicHoliday(holiday.Date)
+		switch {
+		case public:
+			// public holidays falling on weekends are holidays still, their
+			// days off are transferred
+			holiday.Type = model.TypeHoliday
+		case weekend:
+			holiday.Type = model.TypeWeekend
+		default:
+			// a weekday off, which is not a public holiday, could only appear
+			// due to a transfer
+			holiday.Type = model.TypeTransferred
+		}
+	case s.HasClass("preholiday"):
+		holiday.Type = model.TypePreholiday
+	case weekend:
+		// a weekend day, made working by a holiday transfer
+		holiday.Type = model.TypeWorkingWeekend
+	default:
+		return model.Holiday{}, false, nil
+	}
+
+	return holiday, true, nil
+}
+
 func (c *ConsultantRu) getMonthTablesOrdered(doc *goquery.Document) (months []*goquery.Selection, err error) {
 	foundMonths := make([]string, 0, 12)
 	doc.Find("div.row > div.col-md-3 > table.cal").Each(func(monthN int, s *goquery.Selection) {
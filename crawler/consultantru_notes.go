@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mwf/golidays/model"
+)
+
+var (
+	// ruMonthsGenitive are month names, as they are used in dates
+	ruMonthsGenitive = map[string]int{
+		"января":   1,
+		"февраля":  2,
+		"марта":    3,
+		"апреля":   4,
+		"мая":      5,
+		"июня":     6,
+		"июля":     7,
+		"августа":  8,
+		"сентября": 9,
+		"октября":  10,
+		"ноября":   11,
+		"декабря":  12,
+	}
+
+	// e.g. "1, 2, 3, 4, 5, 6 и 8 января — Новогодние каникулы;"
+	namedDaysRe = regexp.MustCompile(`(?i)^((?:\d{1,2}(?:,| и) )*\d{1,2}) ([а-яё]+) [—–-] (.+?)[;.]?$`)
+	// e.g. "с субботы 5 января на четверг 2 мая", year is optional
+	transferRe  = regexp.MustCompile(`(?i)с (?:[а-яё]+ )?(\d{1,2}) ([а-яё]+)(?: (\d{4}) г[а-яё.]*)? на (?:[а-яё]+ )?(\d{1,2}) ([а-яё]+)(?: (\d{4}) г[а-яё.]*)?`)
+	daysSplitRe = regexp.MustCompile(`,| и `)
+)
+
+// note is additional information about the day, published on the page
+type note struct {
+	name   string
+	reason string
+	from   model.Date
+}
+
+// parseNotes parses holiday names and transfers of days off from the page
+// text, which follows the month tables.
+func (c *ConsultantRu) parseNotes(doc *goquery.Document, year int) map[model.Date]note {
+	notes := make(map[model.Date]note)
+	doc.Find("p, li").Each(func(i int, s *goquery.Selection) {
+		text := strings.Join(strings.Fields(s.Text()), " ")
+
+		if m := namedDaysRe.FindStringSubmatch(text); m != nil {
+			month, ok := ruMonthsGenitive[strings.ToLower(m[2])]
+			if !ok {
+				return
+			}
+			for _, dayS := range daysSplitRe.Split(m[1], -1) {
+				day, err := strconv.Atoi(strings.TrimSpace(dayS))
+				if err != nil {
+					continue
+				}
+				date := model.NewDate(year, time.Month(month), day)
+				n := notes[date]
+				n.name = m[3]
+				notes[date] = n
+			}
+			return
+		}
+
+		for _, m := range transferRe.FindAllStringSubmatch(text, -1) {
+			from, ok := parseNoteDate(m[1], m[2], m[3], year)
+			if !ok {
+				continue
+			}
+			to, ok := parseNoteDate(m[4], m[5], m[6], year)
+			if !ok {
+				continue
+			}
+			n := notes[to]
+			n.reason = "перенос выходного дня " + m[0]
+			n.from = from
+			notes[to] = n
+		}
+	})
+
+	return notes
+}
+
+func parseNoteDate(dayS, monthS, yearS string, year int) (model.Date, bool) {
+	month, ok := ruMonthsGenitive[strings.ToLower(monthS)]
+	if !ok {
+		return model.Date{}, false
+	}
+	day, err := strconv.Atoi(dayS)
+	if err != nil {
+		return model.Date{}, false
+	}
+	if yearS != "" {
+		if year, err = strconv.Atoi(yearS); err != nil {
+			return model.Date{}, false
+		}
+	}
+
+	return model.NewDate(year, time.Month(month), day), true
+}
+
+// applyNotes fills holidays with names, reasons and transfers. Public holidays
+// names are taken from the official list, if the page does not provide them.
+func applyNotes(holidays model.Holidays, notes map[model.Date]note) {
+	for i := range holidays {
+		if n, ok := notes[holidays[i].Date]; ok {
+			holidays[i].Name = n.name
+			holidays[i].Reason = n.reason
+			holidays[i].TransferredFrom = n.from
+		}
+
+		if holidays[i].Name == "" && holidays[i].Type != model.TypeWorkingWeekend {
+			if public, ok := model.FindPublicHoliday(holidays[i].Date); ok {
+				holidays[i].Name = public.Name
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mwf/golidays/model"
+)
+
+var (
+	// summaryWeekHours are weekly hours regimes of the summary table columns,
+	// in order of appearance
+	summaryWeekHours = []int{model.Week40, model.Week36, model.Week24}
+
+	quarterRe = regexp.MustCompile(`^(i|ii|iii|iv|1|2|3|4) квартал$`)
+	halfRe    = regexp.MustCompile(`^(i|ii|1|2) полугодие$`)
+	yearRe    = regexp.MustCompile(`^(\d{4} )?год$`)
+
+	periodNumbers = map[string]int{
+		"i":   1,
+		"ii":  2,
+		"iii": 3,
+		"iv":  4,
+		"1":   1,
+		"2":   2,
+		"3":   3,
+		"4":   4,
+	}
+)
+
+// parseSummary parses the summary table: a row per month, quarter, half-year
+// and year with calendar, working days, days off and working hours norms.
+func (c *ConsultantRu) parseSummary(doc *goquery.Document, year int) (model.Summaries, error) {
+	summaries := model.Summaries{}
+
+	var err error
+	doc.Find("table").Not(".cal").Find("tr").EachWithBreak(func(i int, row *goquery.Selection) bool {
+		cells := row.ChildrenFiltered("td")
+		if cells.Length() != 4+len(summaryWeekHours) {
+			return true
+		}
+
+		label := strings.ToLower(strings.Join(strings.Fields(cells.First().Text()), " "))
+		from, to, ok := summaryPeriod(label, year)
+		if !ok {
+			return true
+		}
+
+		var summary model.Summary
+		summary, err = parseSummaryRow(cells.Slice(1, cells.Length()), from, to)
+		if err != nil {
+			err = fmt.Errorf("can't parse summary for %q: %s", label, err)
+			return false
+		}
+
+		summaries = append(summaries, summary)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(summaries) == 0 {
+		return nil, fmt.Errorf("summary table not found")
+	}
+
+	return summaries, nil
+}
+
+// summaryPeriod returns the dates of period, described by the summary row label
+func summaryPeriod(label string, year int) (from, to model.Date, ok bool) {
+	if month, ok := ruMonths[label]; ok {
+		from = model.NewDate(year, time.Month(month), 1)
+		return from, from.AddDate(0, 1, -1), true
+	}
+
+	months := 0
+	if m := quarterRe.FindStringSubmatch(label); m != nil {
+		months = 3
+		from = model.NewDate(year, time.Month((periodNumbers[m[1]]-1)*months+1), 1)
+	} else if m := halfRe.FindStringSubmatch(label); m != nil {
+		months = 6
+		from = model.NewDate(year, time.Month((periodNumbers[m[1]]-1)*months+1), 1)
+	} else if yearRe.MatchString(label) {
+		months = 12
+		from = model.NewDate(year, time.January, 1)
+	} else {
+		return from, to, false
+	}
+
+	return from, from.AddDate(0, months, -1), true
+}
+
+// parseSummaryRow parses values of the summary row: calendar days, working
+// days, days off and working hours norms
+func parseSummaryRow(cells *goquery.Selection, from, to model.Date) (model.Summary, error) {
+	values := cells.Map(func(i int, s *goquery.Selection) string {
+		return strings.TrimSpace(s.Text())
+	})
+
+	days := make([]int, 3)
+	for i := range days {
+		n, err := strconv.Atoi(values[i])
+		if err != nil {
+			return model.Summary{}, fmt.Errorf("can't parse days from '%s'", values[i])
+		}
+		days[i] = n
+	}
+
+	summary := model.Summary{
+		From:         from,
+		To:           to,
+		CalendarDays: days[0],
+		WorkingDays:  days[1],
+		DaysOff:      days[2],
+		Hours:        make(map[int]time.Duration, len(summaryWeekHours)),
+	}
+
+	for i, week := range summaryWeekHours {
+		valueS := values[3+i]
+		hours, err := strconv.ParseFloat(strings.Replace(valueS, ",", ".", 1), 64)
+		if err != nil {
+			return model.Summary{}, fmt.Errorf("can't parse hours from '%s'", valueS)
+		}
+		summary.Hours[week] = time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+	}
+
+	return summary, nil
+}
+
+// checkSummaries checks that holidays match the summaries, which is a sign
+// of the correctly parsed page
+func checkSummaries(holidays model.Holidays, summaries model.Summaries) error {
+	for _, s := range summaries {
+		norm := model.NormOf(s.From, s.To, holidays)
+
+		period := fmt.Sprintf("%s - %s", s.From, s.To)
+		if norm.CalendarDays != s.CalendarDays {
+			return fmt.Errorf("summary mismatch for %s: calendar days %d != %d", period, norm.CalendarDays, s.CalendarDays)
+		}
+		if norm.WorkingDays != s.WorkingDays {
+			return fmt.Errorf("summary mismatch for %s: working days %d != %d", period, norm.WorkingDays, s.WorkingDays)
+		}
+		if norm.DaysOff != s.DaysOff {
+			return fmt.Errorf("summary mismatch for %s: days off %d != %d", period, norm.DaysOff, s.DaysOff)
+		}
+		for week, hours := range s.Hours {
+			if norm.Hours(week) != hours {
+				return fmt.Errorf("summary mismatch for %s: %d-hours week norm %s != %s", period, week, norm.Hours(week), hours)
+			}
+		}
+	}
+
+	return nil
+}
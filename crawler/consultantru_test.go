@@ -0,0 +1,188 @@
+package crawler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mwf/golidays/model"
+)
+
+func loadDocument(t *testing.T, replace ...string) *goquery.Document {
+	data, err := ioutil.ReadFile("testdata/consultantru_2019.html")
+	if err != nil {
+		t.Fatalf("can't read test data: %s", err)
+	}
+	for i := 0; i+1 < len(replace); i += 2 {
+		data = bytes.Replace(data, []byte(replace[i]), []byte(replace[i+1]), 1)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("can't parse test data: %s", err)
+	}
+	return doc
+}
+
+func TestParseYear(t *testing.T) {
+	c := NewConsultantRu()
+
+	holidays, err := c.parseYear(loadDocument(t), 2019)
+	if err != nil {
+		t.Fatalf("parseYear failed: %s", err)
+	}
+
+	if len(holidays) != 124 {
+		t.Errorf("parsed %d holidays, expected 124", len(holidays))
+	}
+
+	expected := map[model.Date]model.HolidayType{
+		model.NewDate(2019, 1, 1):   model.TypeHoliday,
+		model.NewDate(2019, 1, 5):   model.TypeHoliday,
+		model.NewDate(2019, 1, 12):  model.TypeWeekend,
+		model.NewDate(2019, 2, 22):  model.TypePreholiday,
+		model.NewDate(2019, 2, 23):  model.TypeHoliday,
+		model.NewDate(2019, 2, 24):  model.TypeWeekend,
+		model.NewDate(2019, 5, 2):   model.TypeTransferred,
+		model.NewDate(2019, 5, 9):   model.TypeHoliday,
+		model.NewDate(2019, 5, 10):  model.TypeTransferred,
+		model.NewDate(2019, 12, 31): model.TypePreholiday,
+	}
+	for _, h := range holidays {
+		if typ, ok := expected[h.Date]; ok {
+			if h.Type != typ {
+				t.Errorf("holiday %s type %q != expected %q", h.Date, h.Type, typ)
+			}
+			delete(expected, h.Date)
+		}
+	}
+	if len(expected) > 0 {
+		t.Errorf("holidays not found: %v", expected)
+	}
+}
+
+func TestParseSummary(t *testing.T) {
+	c := NewConsultantRu()
+
+	summaries, err := c.parseSummary(loadDocument(t), 2019)
+	if err != nil {
+		t.Fatalf("parseSummary failed: %s", err)
+	}
+
+	// 12 months, 4 quarters, 2 half-years and the year
+	if len(summaries) != 19 {
+		t.Fatalf("parsed %d summaries, expected 19", len(summaries))
+	}
+
+	year := summaries[len(summaries)-1]
+	if year.From != model.NewDate(2019, 1, 1) || year.To != model.NewDate(2019, 12, 31) {
+		t.Errorf("unexpected year summary bounds %s - %s", year.From, year.To)
+	}
+	if year.CalendarDays != 365 || year.WorkingDays != 247 || year.DaysOff != 118 {
+		t.Errorf("unexpected year summary %#v", year)
+	}
+	if year.Hours[40] != 1970*time.Hour || year.Hours[36] != 1772*time.Hour+24*time.Minute {
+		t.Errorf("unexpected year hours %v", year.Hours)
+	}
+
+	q2 := summaries[7]
+	if q2.From != model.NewDate(2019, 4, 1) || q2.To != model.NewDate(2019, 6, 30) {
+		t.Errorf("unexpected second quarter bounds %s - %s", q2.From, q2.To)
+	}
+}
+
+func TestParseYear_summaryMismatch(t *testing.T) {
+	c := NewConsultantRu()
+
+	doc := loadDocument(t,
+		"<td>Январь</td><td>31</td><td>17</td><td>14</td>",
+		"<td>Январь</td><td>31</td><td>18</td><td>13</td>",
+	)
+	if _, err := c.parseYear(doc, 2019); err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+}
+
+func TestParseYear_noSummary(t *testing.T) {
+	c := NewConsultantRu()
+
+	doc := loadDocument(t, `<table class="table">`, `<table class="cal">`)
+	if _, err := c.parseYear(doc, 2019); err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+}
+
+func TestParseYear_workingWeekend(t *testing.T) {
+	c := NewConsultantRu()
+
+	// swap saturday 12 January with thursday 10 January to keep the summary
+	doc := loadDocument(t,
+		`<td class="weekend">12</td>`, `<td>12</td>`,
+		`<td>10</td>`, `<td class="weekend">10</td>`,
+	)
+	holidays, err := c.parseYear(doc, 2019)
+	if err != nil {
+		t.Fatalf("parseYear failed: %s", err)
+	}
+
+	expected := map[model.Date]model.HolidayType{
+		model.NewDate(2019, 1, 10): model.TypeTransferred,
+		model.NewDate(2019, 1, 12): model.TypeWorkingWeekend,
+	}
+	for _, h := range holidays {
+		if typ, ok := expected[h.Date]; ok {
+			if h.Type != typ {
+				t.Errorf("holiday %s type %q != expected %q", h.Date, h.Type, typ)
+			}
+			delete(expected, h.Date)
+		}
+	}
+	if len(expected) > 0 {
+		t.Errorf("holidays not found: %v", expected)
+	}
+}
+
+func TestParseYear_notes(t *testing.T) {
+	c := NewConsultantRu()
+
+	holidays, err := c.parseYear(loadDocument(t), 2019)
+	if err != nil {
+		t.Fatalf("parseYear failed: %s", err)
+	}
+
+	expected := map[model.Date]model.Holiday{
+		model.NewDate(2019, 1, 6): {
+			Date: model.NewDate(2019, 1, 6),
+			Type: model.TypeHoliday,
+			Name: "Новогодние каникулы",
+		},
+		model.NewDate(2019, 5, 9): {
+			Date: model.NewDate(2019, 5, 9),
+			Type: model.TypeHoliday,
+			Name: "День Победы",
+		},
+		model.NewDate(2019, 5, 10): {
+			Date:            model.NewDate(2019, 5, 10),
+			Type:            model.TypeTransferred,
+			Reason:          "перенос выходного дня с субботы 23 февраля на пятницу 10 мая",
+			TransferredFrom: model.NewDate(2019, 2, 23),
+		},
+		model.NewDate(2019, 5, 11): {
+			Date: model.NewDate(2019, 5, 11),
+			Type: model.TypeWeekend,
+		},
+	}
+	for _, h := range holidays {
+		if e, ok := expected[h.Date]; ok {
+			if h != e {
+				t.Errorf("holiday %#v != expected %#v", h, e)
+			}
+			delete(expected, h.Date)
+		}
+	}
+	if len(expected) > 0 {
+		t.Errorf("holidays not found: %v", expected)
+	}
+}
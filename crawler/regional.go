@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"sort"
+
+	"github.com/mwf/golidays/model"
+)
+
+// Regional is a crawler for regional calendars. It takes the country calendar
+// from the base crawler and adds fixed-date regional holidays.
+type Regional struct {
+	base     Crawler
+	holidays []model.PublicHoliday
+}
+
+var _ Crawler = &Regional{}
+
+// NewRegional returns Regional crawler, adding holidays to the base calendar
+func NewRegional(base Crawler, holidays []model.PublicHoliday) *Regional {
+	return &Regional{
+		base:     base,
+		holidays: holidays,
+	}
+}
+
+func (r *Regional) ScrapeYear(year int) (model.Holidays, error) {
+	holidays, err := r.base.ScrapeYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[model.Date]int, len(holidays))
+	for i, h := range holidays {
+		byDate[h.Date] = i
+	}
+
+	set := func(h model.Holiday) {
+		if i, ok := byDate[h.Date]; ok {
+			holidays[i] = h
+			return
+		}
+		byDate[h.Date] = len(holidays)
+		holidays = append(holidays, h)
+	}
+
+	for _, regional := range r.holidays {
+		if !regional.InEffect(year) {
+			continue
+		}
+
+		date := model.NewDate(year, regional.Month, regional.Day)
+		if i, ok := byDate[date]; ok && holidays[i].Type != model.TypePreholiday && holidays[i].Type != model.TypeWorkingWeekend {
+			// already a day off
+			continue
+		}
+		set(model.Holiday{Date: date, Type: model.TypeHoliday, Name: regional.Name})
+
+		// the working day before a holiday is contracted
+		prev := date.AddDays(-1)
+		if i, ok := byDate[prev]; !ok || holidays[i].Type == model.TypeWorkingWeekend {
+			set(model.Holiday{Date: prev, Type: model.TypePreholiday})
+		}
+	}
+
+	sort.Sort(model.HolidaysByDate(holidays))
+	return holidays, nil
+}
@@ -0,0 +1,49 @@
+package crawler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+)
+
+type staticCrawler model.Holidays
+
+func (c staticCrawler) ScrapeYear(year int) (model.Holidays, error) {
+	return append(model.Holidays{}, c...), nil
+}
+
+func TestRegionalScrapeYear(t *testing.T) {
+	base := staticCrawler{
+		{Date: model.NewDate(2019, 8, 31), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 11, 2), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 11, 3), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 11, 4), Type: model.TypeHoliday, Name: "День народного единства"},
+	}
+	c := NewRegional(base, []model.PublicHoliday{
+		{Month: time.August, Day: 30, Name: "День Республики Татарстан"},
+		{Month: time.November, Day: 3, Name: "Праздник на выходной"},
+		{Month: time.November, Day: 6, Name: "День Конституции Республики Татарстан"},
+		{Month: time.December, Day: 1, Name: "Отменённый праздник", Until: 2018},
+	})
+
+	holidays, err := c.ScrapeYear(2019)
+	if err != nil {
+		t.Fatalf("ScrapeYear failed: %s", err)
+	}
+
+	expected := model.Holidays{
+		{Date: model.NewDate(2019, 8, 29), Type: model.TypePreholiday},
+		{Date: model.NewDate(2019, 8, 30), Type: model.TypeHoliday, Name: "День Республики Татарстан"},
+		{Date: model.NewDate(2019, 8, 31), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 11, 2), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 11, 3), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 11, 4), Type: model.TypeHoliday, Name: "День народного единства"},
+		{Date: model.NewDate(2019, 11, 5), Type: model.TypePreholiday},
+		{Date: model.NewDate(2019, 11, 6), Type: model.TypeHoliday, Name: "День Конституции Республики Татарстан"},
+	}
+	if !reflect.DeepEqual(holidays, expected) {
+		t.Errorf("holidays %#v != expected %#v", holidays, expected)
+	}
+}
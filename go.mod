@@ -4,5 +4,9 @@ go 1.12
 
 require (
 	github.com/PuerkitoBio/goquery v1.5.0
+	github.com/alicebob/miniredis/v2 v2.10.1
+	github.com/go-redis/redis v6.15.9+incompatible
+	github.com/mattn/go-sqlite3 v1.14.6
+	go.etcd.io/bbolt v1.3.6
 	gopkg.in/yaml.v2 v2.2.2
 )
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Calendar identifies a production calendar by a country and an optional
+// region, e.g. RU or RU-TA. Country is ISO 3166-1 alpha-2 code, region is
+// a subdivision part of ISO 3166-2 code.
+type Calendar struct {
+	Country string
+	Region  string
+}
+
+var (
+	// CalendarRU is the federal calendar of the Russian Federation
+	CalendarRU = Calendar{Country: "RU"}
+	// CalendarRUTatarstan is the calendar of the Republic of Tatarstan
+	CalendarRUTatarstan = Calendar{Country: "RU", Region: "TA"}
+	// CalendarRUBashkortostan is the calendar of the Republic of Bashkortostan
+	CalendarRUBashkortostan = Calendar{Country: "RU", Region: "BA"}
+)
+
+// ParseCalendar parses Calendar from "RU" or "RU-TA" form
+func ParseCalendar(s string) (Calendar, error) {
+	parts := strings.SplitN(strings.ToUpper(strings.TrimSpace(s)), "-", 2)
+	c := Calendar{Country: parts[0]}
+	if len(parts) == 2 {
+		c.Region = parts[1]
+	}
+
+	if !c.Valid() {
+		return Calendar{}, fmt.Errorf("invalid calendar %q", s)
+	}
+	return c, nil
+}
+
+// Valid reports if the calendar has a two-letter country code and a region
+// without separators
+func (c Calendar) Valid() bool {
+	return len(c.Country) == 2 && (c.Region == "" || !strings.Contains(c.Region, "-"))
+}
+
+// IsRegional reports if the calendar is a regional one
+func (c Calendar) IsRegional() bool {
+	return c.Region != ""
+}
+
+// CountryCalendar returns the country calendar, the regional one is based on
+func (c Calendar) CountryCalendar() Calendar {
+	return Calendar{Country: c.Country}
+}
+
+func (c Calendar) String() string {
+	if c.Region == "" {
+		return c.Country
+	}
+	return c.Country + "-" + c.Region
+}
+
+// MarshalText implements encoding.TextMarshaler, which allows to use
+// Calendar as a map key in JSON and YAML
+func (c Calendar) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (c *Calendar) UnmarshalText(text []byte) error {
+	calendar, err := ParseCalendar(string(text))
+	if err != nil {
+		return err
+	}
+	*c = calendar
+	return nil
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestParseCalendar(t *testing.T) {
+	cases := map[string]Calendar{
+		"RU":     CalendarRU,
+		"ru-ta":  CalendarRUTatarstan,
+		" RU-BA": CalendarRUBashkortostan,
+	}
+	for s, expected := range cases {
+		c, err := ParseCalendar(s)
+		if err != nil {
+			t.Fatalf("ParseCalendar(%q) failed: %s", s, err)
+		}
+		if c != expected {
+			t.Errorf("ParseCalendar(%q) = %s, expected %s", s, c, expected)
+		}
+	}
+
+	for _, s := range []string{"", "RUS", "RU-TA-X"} {
+		if _, err := ParseCalendar(s); err == nil {
+			t.Errorf("ParseCalendar(%q) should fail", s)
+		}
+	}
+
+	if s := CalendarRUTatarstan.String(); s != "RU-TA" {
+		t.Errorf("calendar string %q != RU-TA", s)
+	}
+}
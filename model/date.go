@@ -0,0 +1,156 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// DateLayout is a layout of Date text representation
+const DateLayout = "2006-01-02"
+
+// Date is a civil date without time and location, e.g. 2019-05-09.
+// The zero value is an unset date.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns normalized Date, e.g. 2019-02-29 becomes 2019-03-01
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date of t in t's location
+func DateOf(t time.Time) Date {
+	var d Date
+	d.Year, d.Month, d.Day = t.Date()
+	return d
+}
+
+// DateIn returns the date of t in the location
+func DateIn(t time.Time, loc *time.Location) Date {
+	return DateOf(t.In(loc))
+}
+
+// ParseDate parses Date from "2006-01-02" layout. RFC 3339 timestamps are
+// accepted as well, dates were stored this way before.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		var rfcErr error
+		if t, rfcErr = time.Parse(time.RFC3339, s); rfcErr != nil {
+			return Date{}, err
+		}
+	}
+
+	return DateOf(t), nil
+}
+
+// In returns the midnight of the date in the location
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports if the date is unset
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.In(time.UTC).Format(DateLayout)
+}
+
+// Weekday returns the day of the week of the date
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+// YearDay returns the day of the year of the date, in the range [1,365] for
+// non-leap years, and [1,366] in leap years
+func (d Date) YearDay() int {
+	return d.In(time.UTC).YearDay()
+}
+
+// AddDays returns the date shifted by n days
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// AddDate returns the date shifted by years, months and days, normalized
+// the same way as time.Time.AddDate
+func (d Date) AddDate(years int, months int, days int) Date {
+	return NewDate(d.Year+years, d.Month+time.Month(months), d.Day+days)
+}
+
+// DaysSince returns the number of days from 'u' to 'd', negative if 'd' is
+// before 'u'
+func (d Date) DaysSince(u Date) int {
+	return int(d.In(time.UTC).Sub(u.In(time.UTC)) / (24 * time.Hour))
+}
+
+// Before reports if 'd' is before 'u'
+func (d Date) Before(u Date) bool {
+	if d.Year != u.Year {
+		return d.Year < u.Year
+	}
+	if d.Month != u.Month {
+		return d.Month < u.Month
+	}
+	return d.Day < u.Day
+}
+
+// After reports if 'd' is after 'u'
+func (d Date) After(u Date) bool {
+	return u.Before(d)
+}
+
+// MarshalText implements encoding.TextMarshaler, used for JSON and YAML as well
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, used for JSON and YAML as well
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Date{}
+		return nil
+	}
+
+	date, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// Value implements driver.Valuer, the zero date is stored as NULL
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+		return nil
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	}
+
+	return fmt.Errorf("can't scan %T into model.Date", src)
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestDateOf(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	ts := time.Date(2019, 5, 8, 22, 30, 0, 0, time.UTC)
+
+	if d := DateOf(ts); d != NewDate(2019, 5, 8) {
+		t.Errorf("DateOf(%s) = %s", ts, d)
+	}
+	if d := DateIn(ts, moscow); d != NewDate(2019, 5, 9) {
+		t.Errorf("DateIn(%s, MSK) = %s", ts, d)
+	}
+	if in := NewDate(2019, 5, 9).In(moscow); !in.Equal(time.Date(2019, 5, 8, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("In(MSK) = %s", in)
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	d := NewDate(2019, 12, 31)
+
+	if next := d.AddDays(1); next != NewDate(2020, 1, 1) {
+		t.Errorf("AddDays(1) = %s", next)
+	}
+	if prev := NewDate(2020, 3, 1).AddDays(-1); prev != NewDate(2020, 2, 29) {
+		t.Errorf("AddDays(-1) = %s", prev)
+	}
+	if month := NewDate(2019, 1, 31).AddDate(0, 1, 0); month != NewDate(2019, 3, 3) {
+		t.Errorf("AddDate(0, 1, 0) = %s", month)
+	}
+	if n := NewDate(2020, 12, 31).DaysSince(NewDate(2020, 1, 1)); n != 365 {
+		t.Errorf("DaysSince = %d", n)
+	}
+	if !d.After(NewDate(2019, 12, 30)) || d.Before(NewDate(2019, 12, 30)) {
+		t.Errorf("%s must be after 2019-12-30", d)
+	}
+	if d.Weekday() != time.Tuesday || d.YearDay() != 365 {
+		t.Errorf("unexpected weekday %s or year day %d", d.Weekday(), d.YearDay())
+	}
+}
+
+func TestDateMarshal(t *testing.T) {
+	h := Holiday{Date: NewDate(2019, 5, 10), Type: TypeTransferred, TransferredFrom: NewDate(2019, 2, 23)}
+
+	bytes, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %s", err)
+	}
+	expected := `{"date":"2019-05-10","type":"transferred","transferred_from":"2019-02-23"}`
+	if string(bytes) != expected {
+		t.Errorf("json %s != expected %s", bytes, expected)
+	}
+	var fromJSON Holiday
+	if err := json.Unmarshal(bytes, &fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal failed: %s", err)
+	}
+	if fromJSON != h {
+		t.Errorf("unmarshaled %#v != original %#v", fromJSON, h)
+	}
+
+	// the unset date is an empty string
+	unset := Holiday{Date: NewDate(2019, 5, 9), Type: TypeHoliday}
+	bytes, err = json.Marshal(unset)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %s", err)
+	}
+	expected = `{"date":"2019-05-09","type":"holiday","transferred_from":""}`
+	if string(bytes) != expected {
+		t.Errorf("json %s != expected %s", bytes, expected)
+	}
+	fromJSON = Holiday{}
+	if err := json.Unmarshal(bytes, &fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal failed: %s", err)
+	}
+	if fromJSON != unset {
+		t.Errorf("unmarshaled %#v != original %#v", fromJSON, unset)
+	}
+
+	bytes, err = yaml.Marshal(unset)
+	if err != nil {
+		t.Fatalf("yaml.Marshal failed: %s", err)
+	}
+	expected = "date: \"2019-05-09\"\ntype: holiday\n"
+	if string(bytes) != expected {
+		t.Errorf("yaml %q != expected %q", bytes, expected)
+	}
+}
+
+func TestDateUnmarshalYAML_legacy(t *testing.T) {
+	var h Holiday
+	if err := yaml.Unmarshal([]byte("date: 2019-05-09T00:00:00Z\ntype: holiday\n"), &h); err != nil {
+		t.Fatalf("yaml.Unmarshal failed: %s", err)
+	}
+	if h.Date != NewDate(2019, 5, 9) {
+		t.Errorf("unmarshaled date %s != 2019-05-09", h.Date)
+	}
+}
+
+func TestDateSQL(t *testing.T) {
+	d := NewDate(2019, 5, 9)
+
+	v, err := d.Value()
+	if err != nil || v != "2019-05-09" {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if v, _ := (Date{}).Value(); v != nil {
+		t.Errorf("zero date value %v != nil", v)
+	}
+
+	for _, src := range []interface{}{"2019-05-09", []byte("2019-05-09"), d.In(time.UTC)} {
+		var scanned Date
+		if err := scanned.Scan(src); err != nil {
+			t.Fatalf("Scan(%#v) failed: %s", src, err)
+		}
+		if scanned != d {
+			t.Errorf("Scan(%#v) = %s", src, scanned)
+		}
+	}
+
+	var scanned Date
+	if err := scanned.Scan(42); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+}
@@ -9,13 +9,26 @@ type HolidayType string
 
 const (
 	TypeWeekend    = HolidayType("weekend")    // just an ordinary weekend day
-	TypeHoliday    = HolidayType("holiday")    // a holiday, real or shifted from the weekend
+	TypeHoliday    = HolidayType("holiday")    // a public holiday, see PublicHolidays
 	TypePreholiday = HolidayType("preholiday") // a preholiday day considered contracted
+
+	TypeWorkingWeekend = HolidayType("working_weekend") // a weekend day, which is working due to a holiday transfer
+	TypeTransferred    = HolidayType("transferred")     // a day off, transferred from a weekend or a holiday
+
+	TypeWorkday = HolidayType("workday") // an ordinary working day, used to remove days off in overlays
 )
 
 type Holiday struct {
-	Date time.Time   `json:"date" yaml:"date"`
+	Date Date        `json:"date" yaml:"date"`
 	Type HolidayType `json:"type" yaml:"type"`
+	// Name is an optional holiday name, e.g. "День Победы"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Reason optionally describes why the day is off or working
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// TransferredFrom is an optional date, the day off was transferred from.
+	// JSON has no omitempty for structs, the unset date is encoded as "" and
+	// decoded back to the zero date, YAML omits it.
+	TransferredFrom Date `json:"transferred_from" yaml:"transferred_from,omitempty"`
 }
 
 type Holidays []Holiday
@@ -27,6 +40,8 @@ func (h HolidaysByDate) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 func (h HolidaysByDate) Less(i, j int) bool { return h[i].Date.Before(h[j].Date) }
 
 // NewDay returns time.Time instance, representing day
+//
+// Deprecated: use NewDate, holidays are keyed by Date.
 func NewDay(year int, month time.Month, day int) time.Time {
 	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
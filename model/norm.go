@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+)
+
+// Weekly working hours regimes, used in the official production calendar
+const (
+	Week40 = 40
+	Week39 = 39
+	Week36 = 36
+	Week24 = 24
+)
+
+// workdaysPerWeek is a number of working days in a five-day week, which is
+// used to calculate daily norms for any weekly regime
+const workdaysPerWeek = 5
+
+// Norm is a working time norm for a period of days
+type Norm struct {
+	From           Date `json:"from" yaml:"from"`
+	To             Date `json:"to" yaml:"to"`
+	CalendarDays   int  `json:"calendar_days" yaml:"calendar_days"`
+	WorkingDays    int  `json:"working_days" yaml:"working_days"`
+	DaysOff        int  `json:"days_off" yaml:"days_off"`
+	PreholidayDays int  `json:"preholiday_days" yaml:"preholiday_days"`
+}
+
+// Hours returns working hours norm for the regime of weekHours hours per week.
+// The daily norm is weekHours/5, every preholiday day is shortened by one hour.
+func (n Norm) Hours(weekHours int) time.Duration {
+	daily := time.Duration(weekHours) * time.Hour / workdaysPerWeek
+	return time.Duration(n.WorkingDays)*daily - time.Duration(n.PreholidayDays)*time.Hour
+}
+
+// Add returns the norm of both periods, 'next' must follow 'n'
+func (n Norm) Add(next Norm) Norm {
+	return Norm{
+		From:           n.From,
+		To:             next.To,
+		CalendarDays:   n.CalendarDays + next.CalendarDays,
+		WorkingDays:    n.WorkingDays + next.WorkingDays,
+		DaysOff:        n.DaysOff + next.DaysOff,
+		PreholidayDays: n.PreholidayDays + next.PreholidayDays,
+	}
+}
+
+// NormOf calculates the norm for the period between 'from' and 'to' dates
+// inclusively, holidays outside the period are ignored.
+func NormOf(from, to Date, holidays Holidays) Norm {
+	n := Norm{
+		From:         from,
+		To:           to,
+		CalendarDays: to.DaysSince(from) + 1,
+	}
+
+	for _, h := range holidays {
+		if h.Date.Before(from) || h.Date.After(to) {
+			continue
+		}
+
+		switch {
+		case IsDayOff(h):
+			n.DaysOff++
+		case h.Type == TypePreholiday:
+			n.PreholidayDays++
+		}
+	}
+	n.WorkingDays = n.CalendarDays - n.DaysOff
+
+	return n
+}
+
+// IsDayOff reports if the holiday is a non-working day.
+// Preholiday days are contracted, but still working, as well as weekend days
+// made working by a holiday transfer.
+func IsDayOff(h Holiday) bool {
+	switch h.Type {
+	case TypeWeekend, TypeHoliday, TypeTransferred:
+		return true
+	}
+	return false
+}
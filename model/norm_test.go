@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormHours(t *testing.T) {
+	// January 2019 from the official summary table
+	n := Norm{CalendarDays: 31, WorkingDays: 17, DaysOff: 14}
+	cases := map[int]time.Duration{
+		Week40: 136 * time.Hour,
+		Week36: 122*time.Hour + 24*time.Minute,
+		Week24: 81*time.Hour + 36*time.Minute,
+	}
+	for week, expected := range cases {
+		if hours := n.Hours(week); hours != expected {
+			t.Errorf("%d-hours week norm %s != expected %s", week, hours, expected)
+		}
+	}
+
+	// March 2019, one preholiday day
+	n = Norm{CalendarDays: 31, WorkingDays: 20, DaysOff: 11, PreholidayDays: 1}
+	cases = map[int]time.Duration{
+		Week40: 159 * time.Hour,
+		Week36: 143 * time.Hour,
+		Week24: 95 * time.Hour,
+	}
+	for week, expected := range cases {
+		if hours := n.Hours(week); hours != expected {
+			t.Errorf("%d-hours week norm %s != expected %s", week, hours, expected)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+)
+
+// PublicHoliday is a fixed-date non-working public holiday, established by
+// article 112 of the Labor Code of the Russian Federation
+type PublicHoliday struct {
+	Month time.Month
+	Day   int
+	Name  string
+	// Since and Until are the first and the last years the holiday is in
+	// effect, zero value means no limit
+	Since int
+	Until int
+}
+
+// PublicHolidays is the official list of fixed public holidays since 2005
+var PublicHolidays = []PublicHoliday{
+	{Month: time.January, Day: 1, Name: "Новогодние каникулы"},
+	{Month: time.January, Day: 2, Name: "Новогодние каникулы"},
+	{Month: time.January, Day: 3, Name: "Новогодние каникулы"},
+	{Month: time.January, Day: 4, Name: "Новогодние каникулы"},
+	{Month: time.January, Day: 5, Name: "Новогодние каникулы"},
+	{Month: time.January, Day: 6, Name: "Новогодние каникулы", Since: 2013},
+	{Month: time.January, Day: 7, Name: "Рождество Христово"},
+	{Month: time.January, Day: 8, Name: "Новогодние каникулы", Since: 2013},
+	{Month: time.February, Day: 23, Name: "День защитника Отечества"},
+	{Month: time.March, Day: 8, Name: "Международный женский день"},
+	{Month: time.May, Day: 1, Name: "Праздник Весны и Труда"},
+	{Month: time.May, Day: 9, Name: "День Победы"},
+	{Month: time.June, Day: 12, Name: "День России"},
+	{Month: time.November, Day: 4, Name: "День народного единства"},
+}
+
+// RegionalPublicHolidays are fixed-date non-working holidays, established by
+// regional laws in addition to PublicHolidays
+var RegionalPublicHolidays = map[Calendar][]PublicHoliday{
+	CalendarRUTatarstan: {
+		{Month: time.August, Day: 30, Name: "День Республики Татарстан"},
+		{Month: time.November, Day: 6, Name: "День Конституции Республики Татарстан"},
+	},
+	CalendarRUBashkortostan: {
+		{Month: time.October, Day: 11, Name: "День Республики Башкортостан"},
+	},
+}
+
+// InEffect reports if the holiday is celebrated in the year
+func (h PublicHoliday) InEffect(year int) bool {
+	return (h.Since == 0 || year >= h.Since) && (h.Until == 0 || year <= h.Until)
+}
+
+// FindPublicHoliday returns the public holiday, falling on the date.
+// If nothing found - returned bool value is false
+func FindPublicHoliday(date Date) (PublicHoliday, bool) {
+	for _, h := range PublicHolidays {
+		if h.Month == date.Month && h.Day == date.Day && h.InEffect(date.Year) {
+			return h, true
+		}
+	}
+
+	return PublicHoliday{}, false
+}
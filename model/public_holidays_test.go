@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindPublicHoliday(t *testing.T) {
+	cases := []struct {
+		date Date
+		name string
+		ok   bool
+	}{
+		{NewDate(2019, 5, 9), "День Победы", true},
+		{NewDate(2019, 5, 10), "", false},
+		{DateOf(time.Date(2019, 11, 4, 23, 0, 0, 0, time.UTC)), "День народного единства", true},
+		{NewDate(2012, 1, 6), "", false},
+		{NewDate(2013, 1, 6), "Новогодние каникулы", true},
+	}
+
+	for _, tc := range cases {
+		h, ok := FindPublicHoliday(tc.date)
+		if ok != tc.ok || h.Name != tc.name {
+			t.Errorf("FindPublicHoliday(%s) = %q, %t; expected %q, %t", tc.date, h.Name, ok, tc.name, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// Summary is an official production calendar summary for a period: a month,
+// a quarter, a half-year or the whole year.
+type Summary struct {
+	From         Date `json:"from" yaml:"from"`
+	To           Date `json:"to" yaml:"to"`
+	CalendarDays int  `json:"calendar_days" yaml:"calendar_days"`
+	WorkingDays  int  `json:"working_days" yaml:"working_days"`
+	DaysOff      int  `json:"days_off" yaml:"days_off"`
+	// Hours contains working hours norms by weekly hours regime, e.g. 40, 36, 24
+	Hours map[int]time.Duration `json:"hours" yaml:"hours"`
+}
+
+type Summaries []Summary
@@ -1,6 +1,7 @@
 package backuper
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/mwf/golidays/model"
 	"io/ioutil"
@@ -26,14 +27,16 @@ type Backuper struct {
 	logger  logger.Logger
 
 	basePath string
+	name     string
 	files    *stringStack
 
 	runOnce sync.Once
 	done    chan struct{}
 }
 
-// New returns new backuper instance
-func New(storage store.Store, period time.Duration, basePath string, maxBackups int, log logger.Logger) (*Backuper, error) {
+// New returns new backuper instance. Backups are stored in basePath directory
+// as "<name>.<datetime>.yml" files.
+func New(storage store.Store, period time.Duration, basePath, name string, maxBackups int, log logger.Logger) (*Backuper, error) {
 	if period < minPeriod {
 		return nil, fmt.Errorf("period is too low: %s < %s", period, minPeriod)
 	}
@@ -54,6 +57,7 @@ func New(storage store.Store, period time.Duration, basePath string, maxBackups
 		period:   period,
 		logger:   log,
 		basePath: basePath,
+		name:     name,
 		files:    newStringStack(maxBackups),
 		done:     make(chan struct{}),
 	}
@@ -63,7 +67,7 @@ func New(storage store.Store, period time.Duration, basePath string, maxBackups
 }
 
 func (b *Backuper) String() string {
-	return fmt.Sprintf("Backuper {period: %s}", b.period)
+	return fmt.Sprintf("Backuper {name: %s, period: %s}", b.name, b.period)
 }
 
 // Run runs asynchronous update loop. Multiple calls do nothing - the loop started
@@ -89,34 +93,36 @@ func (b *Backuper) RestoreStorage() error {
 	}
 	b.logger.Debugf("restoring last backup from '%s'", lastBackupPath)
 
-	bytes, err := ioutil.ReadFile(lastBackupPath)
+	holidays, err := ReadFile(lastBackupPath)
 	if err != nil {
-		return fmt.Errorf("error reading backup '%s': %s", lastBackupPath, err)
+		return err
 	}
 
-	if err := b.restoreData(bytes); err != nil {
-		return err
+	if err := b.storage.Restore(holidays); err != nil {
+		return fmt.Errorf("error restoring storage: %s", err)
 	}
 
 	b.logger.Infof("backup '%s' restored OK", lastBackupPath)
 	return nil
 }
 
-func (b *Backuper) restoreData(bytes []byte) error {
-	holidays := make(model.Holidays, 0)
-	if err := yaml.Unmarshal(bytes, &holidays); err != nil {
-		return fmt.Errorf("error unmarshaling data: %s", err)
+// ReadFile reads holidays from the backup file
+func ReadFile(path string) (model.Holidays, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup '%s': %s", path, err)
 	}
 
-	if err := b.storage.Restore(holidays); err != nil {
-		return fmt.Errorf("error restoring storage: %s", err)
+	holidays := make(model.Holidays, 0)
+	if err := yaml.Unmarshal(bytes, &holidays); err != nil {
+		return nil, fmt.Errorf("error unmarshaling data: %s", err)
 	}
-	return nil
+	return holidays, nil
 }
 
 func (b *Backuper) restoreList() {
 	// try to restore backup list
-	pattern := filepath.Join(b.basePath, "holidays.*\\.yml")
+	pattern := filepath.Join(b.basePath, b.name+".*\\.yml")
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		b.logger.Warningf("backups list restore failed: %s", err)
@@ -174,18 +180,42 @@ func (b *Backuper) perform() error {
 
 func (b *Backuper) generateBackupName() string {
 	dt := time.Now().Format("2006-01-02T15:04")
-	return fmt.Sprintf("holidays.%s.yml", dt)
+	return fmt.Sprintf("%s.%s.yml", b.name, dt)
 }
 
+// collectAndWrite streams holidays to the file, every holiday is marshaled
+// as a single item sequence, together they make the sequence of all holidays
 func (b *Backuper) collectAndWrite(f *os.File) error {
-	holidays := b.storage.Dump()
-	sort.Sort(model.HolidaysByDate(holidays))
-
-	bytes, err := yaml.Marshal(holidays)
+	w := bufio.NewWriter(f)
+
+	var writeErr error
+	count := 0
+	err := b.storage.Each(func(h model.Holiday) bool {
+		bytes, err := yaml.Marshal(model.Holidays{h})
+		if err != nil {
+			writeErr = fmt.Errorf("error marshaling data: %s", err)
+			return false
+		}
+		if _, err := w.Write(bytes); err != nil {
+			writeErr = fmt.Errorf("error writing data: %s", err)
+			return false
+		}
+		count++
+		return true
+	})
 	if err != nil {
-		return fmt.Errorf("error marshaling data: %s", err)
+		return fmt.Errorf("error reading storage: %s", err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	if count == 0 {
+		if _, err := w.WriteString("[]\n"); err != nil {
+			return fmt.Errorf("error writing data: %s", err)
+		}
 	}
-	if _, err := f.Write(bytes); err != nil {
+	if err := w.Flush(); err != nil {
 		return fmt.Errorf("error writing data: %s", err)
 	}
 	return nil
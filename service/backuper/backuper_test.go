@@ -0,0 +1,86 @@
+package backuper
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/logger"
+	"github.com/mwf/golidays/service/store/memory"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golidays")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	holidays := model.Holidays{
+		{Date: model.NewDate(2012, 3, 8), Type: model.TypeHoliday, Name: "Международный женский день"},
+		{Date: model.NewDate(2012, 3, 9), Type: model.TypeHoliday},
+		{Date: model.NewDate(2012, 3, 10), Type: model.TypeWeekend},
+		{Date: model.NewDate(2012, 3, 11), Type: model.TypeWorkingWeekend},
+		{
+			Date:            model.NewDate(2012, 4, 30),
+			Type:            model.TypeHoliday,
+			Reason:          "перенос выходного дня с субботы 28 апреля на понедельник 30 апреля",
+			TransferredFrom: model.NewDate(2012, 4, 28),
+		},
+	}
+	storage := memory.New()
+	if err := storage.Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	b, err := New(storage, time.Minute, dir, "holidays", 1, &logger.NilLogger{})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	if err := b.perform(); err != nil {
+		t.Fatalf("perform failed: %s", err)
+	}
+
+	restored := memory.New()
+	b, err = New(restored, time.Minute, dir, "holidays", 1, &logger.NilLogger{})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	if err := b.RestoreStorage(); err != nil {
+		t.Fatalf("RestoreStorage failed: %s", err)
+	}
+
+	dump := restored.Dump()
+	sort.Sort(model.HolidaysByDate(dump))
+	if !reflect.DeepEqual(dump, holidays) {
+		t.Errorf("restored data %#v != original %#v", dump, holidays)
+	}
+}
+
+func TestBackupRestore_empty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golidays")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := New(memory.New(), time.Minute, dir, "holidays", 1, &logger.NilLogger{})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	if err := b.perform(); err != nil {
+		t.Fatalf("perform failed: %s", err)
+	}
+
+	holidays, err := ReadFile(b.files.Head())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %s", err)
+	}
+	if holidays == nil || len(holidays) != 0 {
+		t.Errorf("restored data %#v should be empty", holidays)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/mwf/golidays/crawler"
+	"github.com/mwf/golidays/model"
 	"github.com/mwf/golidays/service/logger"
 	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/history"
 	"github.com/mwf/golidays/service/store/memory"
 )
 
@@ -18,10 +20,32 @@ const (
 
 // Config is a service configuration data struct
 type Config struct {
+	// Calendar is the default calendar, which uses Updater.Crawler and Storage
+	Calendar model.Calendar
 	Updater  UpdaterConfig
 	Backuper BackuperConfig
 	Storage  store.Store
-	Logger   logger.Logger
+	// Overrides is an optional storage of the default calendar overrides,
+	// enables the overlay, see overlay.Store
+	Overrides store.Store
+	Logger    logger.Logger
+	// History enables version history of calendars, see history.Store
+	History bool
+	// HistoryLog is an optional log of the default calendar versions, e.g.
+	// history.FileLog. Versions are kept in memory and lost on restart, if
+	// the log is nil.
+	HistoryLog history.Log
+	// Calendars are additional calendars, e.g. regional ones
+	Calendars map[model.Calendar]CalendarConfig
+}
+
+// CalendarConfig is a configuration of an additional calendar, updates and
+// backups are performed with the common settings
+type CalendarConfig struct {
+	Crawler    crawler.Crawler
+	Storage    store.Store
+	Overrides  store.Store
+	HistoryLog history.Log
 }
 
 type UpdaterConfig struct {
@@ -39,6 +63,10 @@ type BackuperConfig struct {
 
 // Defaultize sets default values for some config values
 func (c *Config) Defaultize() {
+	if c.Calendar == (model.Calendar{}) {
+		c.Calendar = model.CalendarRU
+	}
+
 	if c.Updater.Period == 0 {
 		c.Updater.Period = defaultUpdatePeriod
 	}
@@ -53,6 +81,12 @@ func (c *Config) Defaultize() {
 	if c.Storage == nil {
 		c.Storage = memory.New()
 	}
+	for id, calConfig := range c.Calendars {
+		if calConfig.Storage == nil {
+			calConfig.Storage = memory.New()
+			c.Calendars[id] = calConfig
+		}
+	}
 
 	if c.Logger == nil {
 		c.Logger = &logger.NilLogger{}
@@ -61,10 +95,25 @@ func (c *Config) Defaultize() {
 
 // Validate checks current config
 func (c *Config) Validate() error {
+	if !c.Calendar.Valid() {
+		return fmt.Errorf("config.Calendar %q is invalid", c.Calendar)
+	}
 	if !c.Updater.Disabled && c.Updater.Crawler == nil {
 		return fmt.Errorf("config.Updater.Crawler is nil")
 	}
 
+	for id, calConfig := range c.Calendars {
+		if !id.Valid() {
+			return fmt.Errorf("config.Calendars: calendar %q is invalid", id)
+		}
+		if id == c.Calendar {
+			return fmt.Errorf("config.Calendars: calendar %s is the default one", id)
+		}
+		if !c.Updater.Disabled && calConfig.Crawler == nil {
+			return fmt.Errorf("config.Calendars[%s].Crawler is nil", id)
+		}
+	}
+
 	if !c.Backuper.Disabled && c.Backuper.BasePath == "" {
 		return fmt.Errorf("config.Backuper.BasePath is empty")
 	}
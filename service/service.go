@@ -9,8 +9,42 @@ import (
 	"github.com/mwf/golidays/service/backuper"
 	"github.com/mwf/golidays/service/logger"
 	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/history"
+	"github.com/mwf/golidays/service/store/notify"
+	"github.com/mwf/golidays/service/store/overlay"
+	"github.com/mwf/golidays/workday"
 )
 
+// Calendar is an interface for holidays of a single calendar
+type Calendar interface {
+	// Getters from Store interface
+	store.HolidayGetter
+	// Queries of holidays by types, see store.HolidayQuerier
+	store.HolidayQuerier
+	// GetTime is Get for the date of t in the location, the same instant
+	// may be different days in different locations
+	GetTime(t time.Time, loc *time.Location) (model.Holiday, bool, error)
+	// GetRangeTime is GetRange for the dates of 'from' and 'to' in the location
+	GetRangeTime(from, to time.Time, loc *time.Location) (model.Holidays, error)
+	// CheckCoverage returns *store.YearNotLoadedError if any year between
+	// 'from' and 'to' dates is not loaded
+	CheckCoverage(from, to model.Date) error
+	// Working days arithmetic on top of the storage
+	workday.Calculator
+
+	// Overlay returns the overlay of user-defined overrides on top of the
+	// crawled calendar, error if overrides are not configured
+	Overlay() (*overlay.Store, error)
+
+	// Subscribe adds the subscriber of data changes, see notify.Store.
+	// Events are changes of the merged view, if overrides are configured,
+	// so changes of overrides are notified as well.
+	Subscribe(fn func(notify.Event)) (unsubscribe func())
+	// History returns the version history of crawled and restored data,
+	// error if history is not enabled
+	History() (*history.Store, error)
+}
+
 // Service is an interface for holidays storage with optional maintenance
 // (periodic updates, backups, etc.)
 type Service interface {
@@ -18,19 +52,47 @@ type Service interface {
 	Run() error
 	// Stop stops all periodic jobs
 	Stop()
-	// Getters from Store interface
-	store.HolidayGetter
+	// Getters of the default calendar
+	Calendar
+
+	// Calendar returns the calendar by its identifier
+	Calendar(calendar model.Calendar) (Calendar, error)
+	// Calendars returns identifiers of all calendars, sorted by name
+	Calendars() []model.Calendar
 
-	// RestoreStorage wipes storage and restores it from the last backup
+	// RestoreStorage wipes storages and restores them from the last backups
 	RestoreStorage() error
 }
 
 // service is a simple Service interface implementation
 type service struct {
+	// default calendar
+	*calendar
+
+	calendars map[model.Calendar]*calendar
+	storages  store.Calendars
+	log       logger.Logger
+}
+
+// calendar is a Calendar implementation with its own storage and maintenance
+type calendar struct {
+	workday.Calculator
+
+	id       model.Calendar
 	updater  *updater.Updater
 	backuper *backuper.Backuper
+	// storage keeps crawled data, notifier and optional history are its
+	// decorators
 	storage  store.Store
-	log      logger.Logger
+	notifier *notify.Store
+	history  *history.Store
+	// overlay is optional, overrides are backed up separately
+	overlay           *overlay.Store
+	overridesNotifier *notify.Store
+	overridesBackuper *backuper.Backuper
+	log               logger.Logger
+	// getter is the merged view, if overlay is enabled
+	getter store.HolidayGetter
 }
 
 func New(config *Config) (Service, error) {
@@ -40,62 +102,252 @@ func New(config *Config) (Service, error) {
 	}
 
 	s := &service{
-		storage: config.Storage,
-		log:     config.Logger,
+		calendars: make(map[model.Calendar]*calendar, len(config.Calendars)+1),
+		storages:  make(store.Calendars, len(config.Calendars)+1),
+		log:       config.Logger,
+	}
+
+	cals := map[model.Calendar]CalendarConfig{
+		config.Calendar: {
+			Crawler:    config.Updater.Crawler,
+			Storage:    config.Storage,
+			Overrides:  config.Overrides,
+			HistoryLog: config.HistoryLog,
+		},
+	}
+	for id, calConfig := range config.Calendars {
+		cals[id] = calConfig
+	}
+
+	for id, calConfig := range cals {
+		c, err := s.newCalendar(config, id, calConfig)
+		if err != nil {
+			return nil, err
+		}
+		s.calendars[id] = c
+		s.storages[id] = c.storage
+	}
+	s.calendar = s.calendars[config.Calendar]
+
+	return s, nil
+}
+
+func (s *service) newCalendar(config *Config, id model.Calendar, calConfig CalendarConfig) (*calendar, error) {
+	c := &calendar{
+		id:       id,
+		notifier: notify.New(calConfig.Storage),
+		log:      s.log,
+	}
+	c.storage = c.notifier
+	// backups are restored through the history as well
+	backupStorage := c.storage
+	if config.History {
+		h, err := history.New(c.notifier, "updater", calConfig.HistoryLog)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %s", id, err)
+		}
+		c.history = h
+		c.storage = c.history
+		backupStorage = c.history.WithSource("backup")
 	}
+	c.getter = c.storage
+	if calConfig.Overrides != nil {
+		c.overridesNotifier = notify.New(calConfig.Overrides)
+		c.overlay = overlay.New(c.storage, c.overridesNotifier)
+		c.getter = c.overlay
+	}
+	c.Calculator = workday.New(c.getter)
 
 	if !config.Updater.Disabled {
-		updater, err := updater.New(config.Storage, config.Updater.Crawler, config.Updater.Period, s.log)
+		updater, err := updater.New(id, c.storage, calConfig.Crawler, config.Updater.Period, s.log)
 		if err != nil {
 			return nil, err
 		}
-		s.updater = updater
+		c.updater = updater
 	}
 
 	if !config.Backuper.Disabled {
+		// keep the old name for the default calendar to restore existing backups
+		suffix := ""
+		if id != config.Calendar {
+			suffix = fmt.Sprintf("-%s", id)
+		}
+
 		b, err := backuper.New(
-			config.Storage, config.Backuper.Period, config.Backuper.BasePath,
+			backupStorage, config.Backuper.Period, config.Backuper.BasePath, "holidays"+suffix,
 			config.Backuper.MaxBackups, s.log)
 		if err != nil {
 			return nil, err
 		}
-		s.backuper = b
+		c.backuper = b
+
+		if c.overlay != nil {
+			b, err := backuper.New(
+				c.overlay.Overrides(), config.Backuper.Period, config.Backuper.BasePath, "overrides"+suffix,
+				config.Backuper.MaxBackups, s.log)
+			if err != nil {
+				return nil, err
+			}
+			c.overridesBackuper = b
+		}
 	}
 
-	return s, nil
+	return c, nil
 }
 
 func (s *service) Run() error {
-	if s.updater != nil {
-		s.updater.Run()
-	}
-	if s.backuper != nil {
-		s.backuper.Run()
+	for _, id := range s.storages.Calendars() {
+		c := s.calendars[id]
+		if c.updater != nil {
+			c.updater.Run()
+		}
+		if c.backuper != nil {
+			c.backuper.Run()
+		}
+		if c.overridesBackuper != nil {
+			c.overridesBackuper.Run()
+		}
 	}
 	return nil
 }
 
 func (s *service) Stop() {
-	if s.updater != nil {
-		s.updater.Stop()
-	}
-	if s.backuper != nil {
-		s.backuper.Stop()
+	for _, id := range s.storages.Calendars() {
+		c := s.calendars[id]
+		if c.updater != nil {
+			c.updater.Stop()
+		}
+		if c.backuper != nil {
+			c.backuper.Stop()
+		}
+		if c.overridesBackuper != nil {
+			c.overridesBackuper.Stop()
+		}
 	}
 }
 
-func (s *service) Get(date time.Time) (model.Holiday, bool, error) {
-	return s.storage.Get(date)
+func (s *service) Calendar(id model.Calendar) (Calendar, error) {
+	c, ok := s.calendars[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown calendar %s", id)
+	}
+
+	return c, nil
 }
 
-func (s *service) GetRange(from, to time.Time) (model.Holidays, error) {
-	return s.storage.GetRange(from, to)
+func (s *service) Calendars() []model.Calendar {
+	return s.storages.Calendars()
 }
 
 func (s *service) RestoreStorage() error {
-	if s.backuper == nil {
+	if s.calendar.backuper == nil {
 		return fmt.Errorf("backuper is disabled")
 	}
 
-	return s.backuper.RestoreStorage()
+	// try to restore every calendar, but report the first failure
+	var firstErr error
+	for _, id := range s.storages.Calendars() {
+		c := s.calendars[id]
+		if err := c.backuper.RestoreStorage(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("calendar %s: %s", id, err)
+		}
+		if c.overridesBackuper == nil {
+			continue
+		}
+		if err := c.overridesBackuper.RestoreStorage(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("calendar %s overrides: %s", id, err)
+		}
+	}
+
+	return firstErr
+}
+
+func (c *calendar) Get(date model.Date) (model.Holiday, bool, error) {
+	return c.getter.Get(date)
+}
+
+func (c *calendar) GetRange(from, to model.Date) (model.Holidays, error) {
+	return c.getter.GetRange(from, to)
+}
+
+func (c *calendar) Years() []int {
+	return c.getter.Years()
+}
+
+func (c *calendar) CheckCoverage(from, to model.Date) error {
+	loaded := make(map[int]bool)
+	for _, year := range c.Years() {
+		loaded[year] = true
+	}
+
+	return store.CheckCoverage(func(year int) bool { return loaded[year] }, from, to)
+}
+
+func (c *calendar) GetTime(t time.Time, loc *time.Location) (model.Holiday, bool, error) {
+	return c.Get(model.DateIn(t, loc))
+}
+
+func (c *calendar) GetRangeTime(from, to time.Time, loc *time.Location) (model.Holidays, error) {
+	return c.GetRange(model.DateIn(from, loc), model.DateIn(to, loc))
+}
+
+func (c *calendar) NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.NextHoliday(c.getter, date, types...)
+}
+
+func (c *calendar) PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.PrevHoliday(c.getter, date, types...)
+}
+
+func (c *calendar) GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	return store.GetRangeByType(c.getter, from, to, types...)
+}
+
+func (c *calendar) CountByType(from, to model.Date) (map[model.HolidayType]int, error) {
+	return store.CountByType(c.getter, from, to)
+}
+
+func (c *calendar) Subscribe(fn func(notify.Event)) (unsubscribe func()) {
+	if c.overlay == nil {
+		return c.notifier.Subscribe(fn)
+	}
+
+	unsubscribeBase := c.notifier.Subscribe(c.mergedEvents(fn, c.overlay.BaseChanged))
+	unsubscribeOverrides := c.overridesNotifier.Subscribe(c.mergedEvents(fn, c.overlay.OverridesChanged))
+	return func() {
+		unsubscribeBase()
+		unsubscribeOverrides()
+	}
+}
+
+// mergedEvents returns the subscriber, which passes events of the merged view
+// to fn. The original event is passed, if the merged view can't be read, so
+// subscribers don't miss the change.
+func (c *calendar) mergedEvents(fn func(notify.Event), merged func(notify.Event) (notify.Event, error)) func(notify.Event) {
+	return func(e notify.Event) {
+		m, err := merged(e)
+		if err != nil {
+			c.log.Warningf("calendar %s: can't get the change of merged view: %s", c.id, err)
+			m = e
+		}
+		if !m.Empty() {
+			fn(m)
+		}
+	}
+}
+
+func (c *calendar) History() (*history.Store, error) {
+	if c.history == nil {
+		return nil, fmt.Errorf("history of calendar %s is disabled", c.id)
+	}
+
+	return c.history, nil
+}
+
+func (c *calendar) Overlay() (*overlay.Store, error) {
+	if c.overlay == nil {
+		return nil, fmt.Errorf("overlay of calendar %s is disabled", c.id)
+	}
+
+	return c.overlay, nil
 }
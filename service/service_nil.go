@@ -1,17 +1,26 @@
 package service
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/history"
+	"github.com/mwf/golidays/service/store/notify"
+	"github.com/mwf/golidays/service/store/overlay"
+	"github.com/mwf/golidays/workday"
 )
 
-// nilService is a Service doing nothing
-type nilService struct{}
+// nilService is a Service doing nothing, every day is a working day for it
+type nilService struct {
+	workday.Calculator
+}
 
 // NewNilService returns Service instance, doing nothing
 func NewNilService() Service {
-	return &nilService{}
+	s := &nilService{}
+	s.Calculator = workday.New(s)
+	return s
 }
 
 func (s *nilService) Run() error {
@@ -20,14 +29,66 @@ func (s *nilService) Run() error {
 
 func (s *nilService) Stop() {}
 
-func (s *nilService) Get(date time.Time) (model.Holiday, bool, error) {
+func (s *nilService) Get(date model.Date) (model.Holiday, bool, error) {
+	return model.Holiday{}, false, nil
+}
+
+func (s *nilService) GetRange(from, to model.Date) (model.Holidays, error) {
+	return nil, nil
+}
+
+func (s *nilService) Years() []int {
+	return nil
+}
+
+func (s *nilService) CheckCoverage(from, to model.Date) error {
+	return nil
+}
+
+func (s *nilService) NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return model.Holiday{}, fmt.Errorf("no holidays after %s", date)
+}
+
+func (s *nilService) PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return model.Holiday{}, fmt.Errorf("no holidays before %s", date)
+}
+
+func (s *nilService) GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	return nil, nil
+}
+
+func (s *nilService) CountByType(from, to model.Date) (map[model.HolidayType]int, error) {
+	return nil, nil
+}
+
+func (s *nilService) GetTime(t time.Time, loc *time.Location) (model.Holiday, bool, error) {
 	return model.Holiday{}, false, nil
 }
 
-func (s *nilService) GetRange(from, to time.Time) (model.Holidays, error) {
+func (s *nilService) GetRangeTime(from, to time.Time, loc *time.Location) (model.Holidays, error) {
 	return nil, nil
 }
 
 func (s *nilService) RestoreStorage() error {
 	return nil
 }
+
+func (s *nilService) Calendar(calendar model.Calendar) (Calendar, error) {
+	return s, nil
+}
+
+func (s *nilService) Calendars() []model.Calendar {
+	return nil
+}
+
+func (s *nilService) Overlay() (*overlay.Store, error) {
+	return nil, fmt.Errorf("overlay is disabled")
+}
+
+func (s *nilService) Subscribe(fn func(notify.Event)) (unsubscribe func()) {
+	return func() {}
+}
+
+func (s *nilService) History() (*history.Store, error) {
+	return nil, fmt.Errorf("history is disabled")
+}
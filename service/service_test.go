@@ -0,0 +1,355 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/history"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/notify"
+)
+
+func TestCalendars(t *testing.T) {
+	federal := memory.New()
+	regional := memory.New()
+	if err := regional.Set(model.Holidays{{Date: model.NewDate(2019, 8, 30), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	srv, err := New(&Config{
+		Updater:  UpdaterConfig{Disabled: true},
+		Backuper: BackuperConfig{Disabled: true},
+		Storage:  federal,
+		Calendars: map[model.Calendar]CalendarConfig{
+			model.CalendarRUTatarstan: {Storage: regional},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	expected := []model.Calendar{model.CalendarRU, model.CalendarRUTatarstan}
+	if !reflect.DeepEqual(srv.Calendars(), expected) {
+		t.Errorf("calendars %v != expected %v", srv.Calendars(), expected)
+	}
+
+	if _, ok, _ := srv.Get(model.NewDate(2019, 8, 30)); ok {
+		t.Errorf("regional holiday found in the default calendar")
+	}
+
+	tatarstan, err := srv.Calendar(model.CalendarRUTatarstan)
+	if err != nil {
+		t.Fatalf("Calendar failed: %s", err)
+	}
+	if _, ok, _ := tatarstan.Get(model.NewDate(2019, 8, 30)); !ok {
+		t.Errorf("regional holiday not found")
+	}
+	workday, err := tatarstan.IsWorkday(model.NewDate(2019, 8, 30))
+	if err != nil || workday {
+		t.Errorf("regional holiday is a working day: %t, %v", workday, err)
+	}
+
+	if _, err := srv.Calendar(model.CalendarRUBashkortostan); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+}
+
+func TestConfigValidate_calendars(t *testing.T) {
+	config := &Config{
+		Backuper: BackuperConfig{Disabled: true},
+		Updater:  UpdaterConfig{Disabled: true},
+		Calendars: map[model.Calendar]CalendarConfig{
+			model.CalendarRU: {},
+		},
+	}
+	config.Defaultize()
+	if err := config.Validate(); err == nil {
+		t.Errorf("default calendar duplicate should fail validation")
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	storage := memory.New()
+	if err := storage.Set(model.Holidays{{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	srv, err := New(&Config{
+		Updater:   UpdaterConfig{Disabled: true},
+		Backuper:  BackuperConfig{Disabled: true},
+		Storage:   storage,
+		Overrides: memory.New(),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	o, err := srv.Overlay()
+	if err != nil {
+		t.Fatalf("Overlay failed: %s", err)
+	}
+	if err := o.Override(model.Holidays{{Date: model.NewDate(2019, 12, 31), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Override failed: %s", err)
+	}
+
+	workday, err := srv.IsWorkday(model.NewDate(2019, 12, 31))
+	if err != nil || workday {
+		t.Errorf("overridden holiday is a working day: %t, %v", workday, err)
+	}
+	if h, _, _ := storage.Get(model.NewDate(2019, 12, 31)); h.Type != model.TypePreholiday {
+		t.Errorf("override is written to the base storage: %#v", h)
+	}
+}
+
+func TestCheckCoverage(t *testing.T) {
+	storage := memory.New()
+	if err := storage.ReplaceYear(2019, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	srv, err := New(&Config{
+		Updater:  UpdaterConfig{Disabled: true},
+		Backuper: BackuperConfig{Disabled: true},
+		Storage:  storage,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	if err := srv.CheckCoverage(model.NewDate(2019, 1, 1), model.NewDate(2019, 12, 31)); err != nil {
+		t.Errorf("CheckCoverage failed: %s", err)
+	}
+	err = srv.CheckCoverage(model.NewDate(2019, 1, 1), model.NewDate(2020, 1, 1))
+	if e, ok := err.(*store.YearNotLoadedError); !ok || e.Year != 2020 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	srv, err := New(&Config{
+		Updater:  UpdaterConfig{Disabled: true},
+		Backuper: BackuperConfig{Disabled: true},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	events := []notify.Event{}
+	srv.Subscribe(func(e notify.Event) {
+		events = append(events, e)
+	})
+
+	// the way the updater writes
+	holidays := model.Holidays{{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday}}
+	storage := srv.(*service).calendar.storage
+	for i := 0; i < 2; i++ {
+		if err := storage.ReplaceYear(2019, holidays); err != nil {
+			t.Fatalf("ReplaceYear failed: %s", err)
+		}
+	}
+
+	expected := []notify.Event{{Added: holidays}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("events %#v != expected %#v", events, expected)
+	}
+}
+
+func TestSubscribe_overrides(t *testing.T) {
+	storage := memory.New()
+	preholiday := model.Holiday{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday}
+	if err := storage.Set(model.Holidays{preholiday}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	srv, err := New(&Config{
+		Updater:   UpdaterConfig{Disabled: true},
+		Backuper:  BackuperConfig{Disabled: true},
+		Storage:   storage,
+		Overrides: memory.New(),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	events := []notify.Event{}
+	unsubscribe := srv.Subscribe(func(e notify.Event) {
+		events = append(events, e)
+	})
+
+	o, err := srv.Overlay()
+	if err != nil {
+		t.Fatalf("Overlay failed: %s", err)
+	}
+	holiday := model.Holiday{Date: preholiday.Date, Type: model.TypeHoliday}
+	if err := o.Override(model.Holidays{holiday}); err != nil {
+		t.Fatalf("Override failed: %s", err)
+	}
+	// the overridden day is not changed in the merged view
+	if err := srv.(*service).calendar.storage.ReplaceYear(2019, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	if err := o.Remove(preholiday.Date); err != nil {
+		t.Fatalf("Remove failed: %s", err)
+	}
+	if err := o.Reset(preholiday.Date); err != nil {
+		t.Fatalf("Reset failed: %s", err)
+	}
+
+	expected := []notify.Event{
+		{Retyped: []notify.Change{{Old: preholiday, New: holiday}}},
+		{Removed: model.Holidays{holiday}},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("events %#v != expected %#v", events, expected)
+	}
+
+	unsubscribe()
+	if err := o.Override(model.Holidays{holiday}); err != nil {
+		t.Fatalf("Override failed: %s", err)
+	}
+	if len(events) != len(expected) {
+		t.Errorf("event is received after unsubscribe: %#v", events[len(expected):])
+	}
+}
+
+func TestHistory(t *testing.T) {
+	srv, err := New(&Config{
+		Updater:  UpdaterConfig{Disabled: true},
+		Backuper: BackuperConfig{Disabled: true},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	if _, err := srv.History(); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+
+	srv, err = New(&Config{
+		Updater:  UpdaterConfig{Disabled: true},
+		Backuper: BackuperConfig{Disabled: true},
+		History:  true,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	hist, err := srv.History()
+	if err != nil {
+		t.Fatalf("History failed: %s", err)
+	}
+
+	// the way the updater writes
+	holidays := model.Holidays{{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday}}
+	if err := srv.(*service).calendar.storage.ReplaceYear(2019, holidays); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	versions := hist.Versions()
+	if len(versions) != 1 || versions[0].Source != "updater" {
+		t.Fatalf("unexpected versions %#v", versions)
+	}
+	if h, ok, err := srv.Get(holidays[0].Date); err != nil || !ok || h != holidays[0] {
+		t.Errorf("holiday %#v, %t, %v != expected %#v", h, ok, err, holidays[0])
+	}
+}
+
+func TestHistory_log(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	storage := memory.New()
+	log := history.NewFileLog(filepath.Join(dir, "history.jsonl"))
+	config := func() *Config {
+		return &Config{
+			Updater:    UpdaterConfig{Disabled: true},
+			Backuper:   BackuperConfig{Disabled: true},
+			Storage:    storage,
+			History:    true,
+			HistoryLog: log,
+		}
+	}
+
+	srv, err := New(config())
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	holidays := model.Holidays{{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday}}
+	if err := srv.(*service).calendar.storage.ReplaceYear(2019, holidays); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	// versions survive the restart
+	srv, err = New(config())
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	hist, err := srv.History()
+	if err != nil {
+		t.Fatalf("History failed: %s", err)
+	}
+	versions := hist.Versions()
+	if len(versions) != 1 || versions[0].Source != "updater" {
+		t.Fatalf("unexpected versions %#v", versions)
+	}
+	initial, err := hist.AsOf(0)
+	if err != nil {
+		t.Fatalf("AsOf failed: %s", err)
+	}
+	if years := initial.Years(); len(years) != 0 {
+		t.Errorf("initial version years %v != expected []", years)
+	}
+}
+
+func TestNextHoliday(t *testing.T) {
+	storage := memory.New()
+	err := storage.Set(model.Holidays{
+		{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday},
+		{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday},
+	})
+	if err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	srv, err := New(&Config{
+		Updater:   UpdaterConfig{Disabled: true},
+		Backuper:  BackuperConfig{Disabled: true},
+		Storage:   storage,
+		Overrides: memory.New(),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	o, err := srv.Overlay()
+	if err != nil {
+		t.Fatalf("Overlay failed: %s", err)
+	}
+	override := model.Holiday{Date: model.NewDate(2019, 12, 30), Type: model.TypeHoliday}
+	if err := o.Override(model.Holidays{override}); err != nil {
+		t.Fatalf("Override failed: %s", err)
+	}
+
+	// queries see overrides
+	h, err := srv.NextHoliday(model.NewDate(2019, 1, 1), model.TypeHoliday)
+	if err != nil {
+		t.Fatalf("NextHoliday failed: %s", err)
+	}
+	if h != override {
+		t.Errorf("next holiday %#v != expected %#v", h, override)
+	}
+
+	counts, err := srv.CountByType(model.NewDate(2019, 1, 1), model.NewDate(2020, 12, 31))
+	if err != nil {
+		t.Fatalf("CountByType failed: %s", err)
+	}
+	if expected := map[model.HolidayType]int{model.TypeHoliday: 2, model.TypePreholiday: 1}; !reflect.DeepEqual(counts, expected) {
+		t.Errorf("counts %v != expected %v", counts, expected)
+	}
+}
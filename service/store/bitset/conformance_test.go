@@ -0,0 +1,14 @@
+package bitset
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		return New(), func() {}
+	})
+}
@@ -0,0 +1,504 @@
+package bitset
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// knownTypes are holiday types supported by the store, a year keeps a bitset
+// of days per type
+var knownTypes = [...]model.HolidayType{
+	model.TypeWeekend,
+	model.TypeHoliday,
+	model.TypePreholiday,
+	model.TypeWorkingWeekend,
+	model.TypeTransferred,
+	model.TypeWorkday,
+}
+
+// daysBefore are numbers of days before the month in a non-leap year
+var daysBefore = [...]int{0, 31, 59, 90, 120, 151, 181, 212, 243, 273, 304, 334}
+
+// Store is a compact in-memory storage, which keeps every year as bitsets of
+// days per holiday type, indexed by the day of the year. Get is a constant
+// time operation and Count is a population count over the bitsets, while
+// GetRange and Dump have to build holidays day by day and are slower than
+// memory.Store ones.
+//
+// Only the known holiday types are supported. Names, reasons and transfer
+// dates are rare, they are kept aside in a map.
+//
+// Like memory.Store, data is published as immutable snapshots, so readers
+// never block.
+type Store struct {
+	// snapshot holds the current years
+	snapshot atomic.Value
+	// mu serializes writers
+	mu sync.Mutex
+}
+
+// years contains loaded years, even without holidays. It must not be
+// modified once published.
+type years map[int]*yearDays
+
+// check if Store implements Store interface
+var _ store.Store = New()
+
+func New() *Store {
+	s := &Store{}
+	s.snapshot.Store(years{})
+	return s
+}
+
+// Set set's holidays to the storage
+func (s *Store) Set(holidays model.Holidays) error {
+	if err := checkHolidays(holidays); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.load()
+	next := current.clone()
+	cloned := make(map[int]bool)
+	for _, h := range holidays {
+		y := next[h.Date.Year]
+		if !cloned[h.Date.Year] {
+			y = y.clone(h.Date.Year)
+			next[h.Date.Year] = y
+			cloned[h.Date.Year] = true
+		}
+		y.set(h)
+	}
+	s.snapshot.Store(next)
+
+	return nil
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	if err := store.CheckYear(year, holidays); err != nil {
+		return err
+	}
+	if err := checkHolidays(holidays); err != nil {
+		return err
+	}
+
+	y := newYear(year)
+	for _, h := range holidays {
+		y.set(h)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.load().clone()
+	next[year] = y
+	s.snapshot.Store(next)
+
+	return nil
+}
+
+// Get finds holiday by date and returns it.
+// If nothing found - returned bool value is false
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	y, ok := s.load()[date.Year]
+	if !ok {
+		return model.Holiday{}, false, &store.YearNotLoadedError{Year: date.Year}
+	}
+	if !valid(date) {
+		return model.Holiday{}, false, nil
+	}
+
+	i := dayIndex(date)
+	for t := range knownTypes {
+		if y.days[t].has(i) {
+			return y.holiday(i, t), true, nil
+		}
+	}
+
+	return model.Holiday{}, false, nil
+}
+
+// GetRange returns holidays between 'from' and 'to' dates.
+// Returns empty slice if no holidays found
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	snapshot, err := s.loadRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if !valid(from) || !valid(to) {
+		return model.Holidays{}, nil
+	}
+
+	size := 0
+	for year := from.Year; year <= to.Year; year++ {
+		all := snapshot[year].union()
+		size += all.count(yearBounds(year, from, to))
+	}
+
+	holidays := make(model.Holidays, 0, size)
+	for year := from.Year; year <= to.Year; year++ {
+		lo, hi := yearBounds(year, from, to)
+		holidays = snapshot[year].appendRange(holidays, lo, hi)
+	}
+
+	return holidays, nil
+}
+
+// Count returns the number of days of the types between 'from' and 'to'
+// dates, all holidays are counted if no types provided
+func (s *Store) Count(from, to model.Date, types ...model.HolidayType) (int, error) {
+	snapshot, err := s.loadRange(from, to)
+	if err != nil {
+		return 0, err
+	}
+	if !valid(from) || !valid(to) {
+		return 0, nil
+	}
+
+	indexes := make([]int, 0, len(types))
+	for _, typ := range types {
+		if t, ok := typeIndex(typ); ok {
+			indexes = append(indexes, t)
+		}
+	}
+
+	count := 0
+	for year := from.Year; year <= to.Year; year++ {
+		y := snapshot[year]
+
+		var days bitset
+		if len(types) == 0 {
+			days = y.union()
+		}
+		for _, t := range indexes {
+			days.or(&y.days[t])
+		}
+		count += days.count(yearBounds(year, from, to))
+	}
+
+	return count, nil
+}
+
+// Dump returns all holidays from store sorted by date
+func (s *Store) Dump() model.Holidays {
+	snapshot := s.load()
+
+	holidays := model.Holidays{}
+	for _, year := range snapshot.sorted() {
+		holidays = snapshot[year].appendRange(holidays, 0, daysIn(year)-1)
+	}
+
+	return holidays
+}
+
+// Each calls fn for all holidays of the current snapshot in date order,
+// until fn returns false. Holidays are decoded by years.
+func (s *Store) Each(fn func(h model.Holiday) bool) error {
+	snapshot := s.load()
+
+	var holidays model.Holidays
+	for _, year := range snapshot.sorted() {
+		holidays = snapshot[year].appendRange(holidays[:0], 0, daysIn(year)-1)
+		for _, h := range holidays {
+			if !fn(h) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	if err := checkHolidays(holidays); err != nil {
+		return err
+	}
+
+	next := years{}
+	for _, h := range holidays {
+		y, ok := next[h.Date.Year]
+		if !ok {
+			y = newYear(h.Date.Year)
+			next[h.Date.Year] = y
+		}
+		y.set(h)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot.Store(next)
+	return nil
+}
+
+// Years returns loaded years in ascending order
+func (s *Store) Years() []int {
+	return s.load().sorted()
+}
+
+// load returns the current snapshot
+func (s *Store) load() years {
+	return s.snapshot.Load().(years)
+}
+
+// loadRange returns the current snapshot, if the range is valid and covered
+// by loaded years
+func (s *Store) loadRange(from, to model.Date) (years, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	snapshot := s.load()
+	if err := store.CheckCoverage(snapshot.loaded, from, to); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// clone returns a copy of the snapshot to be modified by a writer. Years are
+// shared, writers replace them, but never modify.
+func (y years) clone() years {
+	cloned := make(years, len(y)+1)
+	for year, days := range y {
+		cloned[year] = days
+	}
+	return cloned
+}
+
+// loaded reports if the year is loaded
+func (y years) loaded(year int) bool {
+	_, ok := y[year]
+	return ok
+}
+
+// sorted returns loaded years in ascending order
+func (y years) sorted() []int {
+	sorted := make([]int, 0, len(y))
+	for year := range y {
+		sorted = append(sorted, year)
+	}
+	sort.Ints(sorted)
+
+	return sorted
+}
+
+// yearDays keeps days of the year as bitsets per holiday type
+type yearDays struct {
+	year int
+	days [len(knownTypes)]bitset
+	// details contain holidays with name, reason or transfer date by day
+	// index, detailed days are marked to avoid map lookups
+	details  map[int]model.Holiday
+	detailed bitset
+}
+
+func newYear(number int) *yearDays {
+	return &yearDays{
+		year:    number,
+		details: make(map[int]model.Holiday),
+	}
+}
+
+// clone returns a copy of the year, a new one if y is nil
+func (y *yearDays) clone(number int) *yearDays {
+	cloned := newYear(number)
+	if y == nil {
+		return cloned
+	}
+
+	cloned.days, cloned.detailed = y.days, y.detailed
+	for i, h := range y.details {
+		cloned.details[i] = h
+	}
+	return cloned
+}
+
+// set sets the holiday, its type must be supported
+func (y *yearDays) set(h model.Holiday) {
+	i := dayIndex(h.Date)
+	t, _ := typeIndex(h.Type)
+	for j := range y.days {
+		y.days[j].clear(i)
+	}
+	y.days[t].set(i)
+
+	if h.Name != "" || h.Reason != "" || !h.TransferredFrom.IsZero() {
+		y.details[i] = h
+		y.detailed.set(i)
+	} else {
+		delete(y.details, i)
+		y.detailed.clear(i)
+	}
+}
+
+// holiday returns the holiday of the day index and type index
+func (y *yearDays) holiday(i, t int) model.Holiday {
+	if y.detailed.has(i) {
+		return y.details[i]
+	}
+	return model.Holiday{
+		Date: dateOf(y.year, i),
+		Type: knownTypes[t],
+	}
+}
+
+// union returns days of all types
+func (y *yearDays) union() bitset {
+	var all bitset
+	for t := range y.days {
+		all.or(&y.days[t])
+	}
+	return all
+}
+
+// appendRange appends holidays between 'lo' and 'hi' day indexes
+// (inclusive) in date order
+func (y *yearDays) appendRange(holidays model.Holidays, lo, hi int) model.Holidays {
+	all := y.union()
+	all.each(lo, hi, func(i int) {
+		for t := range y.days {
+			if y.days[t].has(i) {
+				holidays = append(holidays, y.holiday(i, t))
+				return
+			}
+		}
+	})
+	return holidays
+}
+
+// checkHolidays returns error if any of holidays has an invalid date or an
+// unsupported type
+func checkHolidays(holidays model.Holidays) error {
+	for _, h := range holidays {
+		if !valid(h.Date) {
+			return fmt.Errorf("holiday has invalid date %04d-%02d-%02d", h.Date.Year, h.Date.Month, h.Date.Day)
+		}
+		if _, ok := typeIndex(h.Type); !ok {
+			return fmt.Errorf("holiday %s has unsupported type %q", h.Date, h.Type)
+		}
+	}
+	return nil
+}
+
+// typeIndex returns the index of the holiday type in knownTypes
+func typeIndex(typ model.HolidayType) (int, bool) {
+	for t := range knownTypes {
+		if knownTypes[t] == typ {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// valid reports if the date is normalized, e.g. not zero or 2019-13-01, only
+// such dates have day indexes
+func valid(date model.Date) bool {
+	return model.NewDate(date.Year, date.Month, date.Day) == date
+}
+
+// dayIndex returns the zero-based day of the year of the valid date
+func dayIndex(date model.Date) int {
+	i := daysBefore[date.Month-1] + date.Day - 1
+	if date.Month > time.February && isLeap(date.Year) {
+		i++
+	}
+	return i
+}
+
+// dateOf returns the date of zero-based day of the year, it's dayIndex reversed
+func dateOf(year, i int) model.Date {
+	if isLeap(year) {
+		switch {
+		case i == daysBefore[time.March-1]:
+			return model.Date{Year: year, Month: time.February, Day: 29}
+		case i > daysBefore[time.March-1]:
+			i--
+		}
+	}
+
+	m := len(daysBefore) - 1
+	for daysBefore[m] > i {
+		m--
+	}
+	return model.Date{Year: year, Month: time.Month(m + 1), Day: i - daysBefore[m] + 1}
+}
+
+// yearBounds returns day indexes of the year, which are between 'from' and
+// 'to' dates
+func yearBounds(year int, from, to model.Date) (lo, hi int) {
+	lo, hi = 0, daysIn(year)-1
+	if year == from.Year {
+		lo = dayIndex(from)
+	}
+	if year == to.Year {
+		hi = dayIndex(to)
+	}
+	return lo, hi
+}
+
+func daysIn(year int) int {
+	if isLeap(year) {
+		return 366
+	}
+	return 365
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// bitset is a set of year days, 6 words hold 384 days
+type bitset [6]uint64
+
+func (b *bitset) set(i int)      { b[i/64] |= 1 << uint(i%64) }
+func (b *bitset) clear(i int)    { b[i/64] &^= 1 << uint(i%64) }
+func (b *bitset) has(i int) bool { return b[i/64]&(1<<uint(i%64)) != 0 }
+
+func (b *bitset) or(other *bitset) {
+	for w := range b {
+		b[w] |= other[w]
+	}
+}
+
+// word returns the word of the bitset, masked to 'lo' and 'hi' bounds
+func (b *bitset) word(w, lo, hi int) uint64 {
+	word := b[w]
+	if w == lo/64 {
+		word &= ^uint64(0) << uint(lo%64)
+	}
+	if w == hi/64 {
+		word &= ^uint64(0) >> uint(63-hi%64)
+	}
+	return word
+}
+
+// count returns the number of days between 'lo' and 'hi' (inclusive)
+func (b *bitset) count(lo, hi int) int {
+	n := 0
+	for w := lo / 64; w <= hi/64; w++ {
+		n += bits.OnesCount64(b.word(w, lo, hi))
+	}
+	return n
+}
+
+// each calls fn for every day between 'lo' and 'hi' (inclusive) in order
+func (b *bitset) each(lo, hi int, fn func(i int)) {
+	for w := lo / 64; w <= hi/64; w++ {
+		word := b.word(w, lo, hi)
+		for word != 0 {
+			fn(w*64 + bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+}
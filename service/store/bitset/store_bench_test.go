@@ -0,0 +1,102 @@
+package bitset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+)
+
+// benchHolidays returns weekends of years 1990-2039
+func benchHolidays() model.Holidays {
+	holidays := model.Holidays{}
+	for d := model.NewDate(1990, 1, 1); d.Year < 2040; d = d.AddDays(1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			holidays = append(holidays, model.Holiday{Date: d, Type: model.TypeWeekend})
+		}
+	}
+	return holidays
+}
+
+func benchStores(b *testing.B) map[string]store.Store {
+	holidays := benchHolidays()
+	stores := map[string]store.Store{
+		"bitset": New(),
+		"memory": memory.New(),
+	}
+	for _, s := range stores {
+		if err := s.Restore(holidays); err != nil {
+			b.Fatalf("Restore failed: %s", err)
+		}
+	}
+	return stores
+}
+
+func BenchmarkGet(b *testing.B) {
+	dates := make([]model.Date, 0, 366)
+	for d := model.NewDate(2019, 1, 1); d.Year == 2019; d = d.AddDays(1) {
+		dates = append(dates, d)
+	}
+
+	for name, s := range benchStores(b) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := s.Get(dates[i%len(dates)]); err != nil {
+					b.Fatalf("Get failed: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkGetRange(b *testing.B, from, to model.Date) {
+	for name, s := range benchStores(b) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := s.GetRange(from, to); err != nil {
+					b.Fatalf("GetRange failed: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetRange_year(b *testing.B) {
+	benchmarkGetRange(b, model.NewDate(2019, 1, 1), model.NewDate(2019, 12, 31))
+}
+
+func BenchmarkGetRange_decades(b *testing.B) {
+	benchmarkGetRange(b, model.NewDate(1995, 3, 1), model.NewDate(2034, 9, 30))
+}
+
+// BenchmarkCount compares Count with counting of GetRange results, the only
+// way for memory.Store
+func BenchmarkCount(b *testing.B) {
+	from, to := model.NewDate(1995, 3, 1), model.NewDate(2034, 9, 30)
+
+	for name, s := range benchStores(b) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if bs, ok := s.(*Store); ok {
+					if _, err := bs.Count(from, to, model.TypeWeekend); err != nil {
+						b.Fatalf("Count failed: %s", err)
+					}
+					continue
+				}
+
+				holidays, err := s.GetRange(from, to)
+				if err != nil {
+					b.Fatalf("GetRange failed: %s", err)
+				}
+				count := 0
+				for _, h := range holidays {
+					if h.Type == model.TypeWeekend {
+						count++
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package bitset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/memory"
+)
+
+// newHolidays returns days off of 2019 and 2020 with some details, 2020 is
+// a leap year
+func newHolidays() model.Holidays {
+	holidays := model.Holidays{}
+	for d := model.NewDate(2019, 1, 1); d.Year < 2021; d = d.AddDays(1) {
+		switch {
+		case d == model.NewDate(2019, 5, 9):
+			holidays = append(holidays, model.Holiday{Date: d, Type: model.TypeHoliday, Name: "День Победы"})
+		case d == model.NewDate(2019, 5, 10):
+			holidays = append(holidays, model.Holiday{
+				Date:            d,
+				Type:            model.TypeTransferred,
+				TransferredFrom: model.NewDate(2019, 1, 5),
+			})
+		case d == model.NewDate(2020, 12, 31):
+			holidays = append(holidays, model.Holiday{Date: d, Type: model.TypePreholiday})
+		case d.Weekday() == time.Saturday || d.Weekday() == time.Sunday:
+			holidays = append(holidays, model.Holiday{Date: d, Type: model.TypeWeekend})
+		}
+	}
+	return holidays
+}
+
+func TestSet_retype(t *testing.T) {
+	store := New()
+	date := model.NewDate(2019, 5, 9)
+	if err := store.Set(model.Holidays{{Date: date, Type: model.TypeHoliday, Name: "День Победы"}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := store.Set(model.Holidays{{Date: date, Type: model.TypeWorkday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	expected := model.Holiday{Date: date, Type: model.TypeWorkday}
+	storedH, _, err := store.Get(date)
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if storedH != expected {
+		t.Errorf("stored data %#v != expected %#v", storedH, expected)
+	}
+}
+
+func TestSet_unsupportedType(t *testing.T) {
+	store := New()
+
+	err := store.Set(model.Holidays{{Date: model.NewDate(2019, 5, 9), Type: model.HolidayType("unknown")}})
+	if err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+	if years := store.Years(); len(years) != 0 {
+		t.Errorf("years %v should be empty after failed Set", years)
+	}
+}
+
+func TestSet_invalidDate(t *testing.T) {
+	store := New()
+
+	invalid := model.Holidays{{Type: model.TypeHoliday}}
+	if err := store.Set(invalid); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if err := store.Restore(invalid); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if err := store.ReplaceYear(0, invalid); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if err := store.Set(model.Holidays{{Date: model.Date{Year: 2019, Month: 2, Day: 29}, Type: model.TypeHoliday}}); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if years := store.Years(); len(years) != 0 {
+		t.Errorf("years %v should be empty after failed writes", years)
+	}
+}
+
+func TestGet_invalidDate(t *testing.T) {
+	store := New()
+	if err := store.Set(newHolidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	invalid := model.Date{Year: 2019, Month: 13, Day: 1}
+	if h, ok, err := store.Get(invalid); err != nil || ok {
+		t.Errorf("unexpected holiday %#v of invalid date, error %v", h, err)
+	}
+	holidays, err := store.GetRange(model.NewDate(2019, 1, 1), invalid)
+	if err != nil {
+		t.Fatalf("GetRange failed: %s", err)
+	}
+	if len(holidays) != 0 {
+		t.Errorf("holidays should be empty: %#v", holidays)
+	}
+	if count, err := store.Count(invalid, invalid); err != nil || count != 0 {
+		t.Errorf("unexpected count %d of invalid dates, error %v", count, err)
+	}
+}
+
+// TestGetRange compares ranges with memory.Store
+func TestGetRange(t *testing.T) {
+	store, mem := New(), memory.New()
+	holidays := newHolidays()
+	if err := store.Restore(holidays); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if err := mem.Restore(holidays); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+
+	ranges := [][2]model.Date{
+		{model.NewDate(2019, 1, 1), model.NewDate(2020, 12, 31)},
+		{model.NewDate(2019, 5, 4), model.NewDate(2019, 5, 12)},
+		{model.NewDate(2019, 12, 28), model.NewDate(2020, 1, 5)},
+		{model.NewDate(2020, 2, 29), model.NewDate(2020, 2, 29)},
+		{model.NewDate(2019, 3, 4), model.NewDate(2019, 3, 8)},
+	}
+	for _, r := range ranges {
+		expected, err := mem.GetRange(r[0], r[1])
+		if err != nil {
+			t.Fatalf("GetRange failed: %s", err)
+		}
+		storedH, err := store.GetRange(r[0], r[1])
+		if err != nil {
+			t.Fatalf("GetRange failed: %s", err)
+		}
+		if !reflect.DeepEqual(storedH, expected) {
+			t.Errorf("range %s - %s: %#v != expected %#v", r[0], r[1], storedH, expected)
+		}
+
+		count, err := store.Count(r[0], r[1])
+		if err != nil {
+			t.Fatalf("Count failed: %s", err)
+		}
+		if count != len(expected) {
+			t.Errorf("range %s - %s: count %d != %d", r[0], r[1], count, len(expected))
+		}
+	}
+
+	if dump := store.Dump(); !reflect.DeepEqual(dump, holidays) {
+		t.Errorf("dump %#v != original %#v", dump, holidays)
+	}
+}
+
+func TestCount(t *testing.T) {
+	store := New()
+	if err := store.Set(newHolidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	from, to := model.NewDate(2019, 5, 1), model.NewDate(2019, 5, 31)
+	counts := map[model.HolidayType]int{
+		model.TypeWeekend:     8,
+		model.TypeHoliday:     1,
+		model.TypeTransferred: 1,
+		model.TypePreholiday:  0,
+	}
+	for typ, expected := range counts {
+		count, err := store.Count(from, to, typ)
+		if err != nil {
+			t.Fatalf("Count failed: %s", err)
+		}
+		if count != expected {
+			t.Errorf("count of %s %d != %d", typ, count, expected)
+		}
+	}
+
+	count, err := store.Count(from, to, model.TypeHoliday, model.TypeTransferred)
+	if err != nil {
+		t.Fatalf("Count failed: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("count of holidays and transferred days %d != 2", count)
+	}
+}
+
+func TestDateOf(t *testing.T) {
+	for d := model.NewDate(2019, 1, 1); d.Year < 2021; d = d.AddDays(1) {
+		if date := dateOf(d.Year, dayIndex(d)); date != d {
+			t.Errorf("date of %s index %d is %s", d, dayIndex(d), date)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package bolt
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		db, _, cleanup := openDB(t)
+		return newStore(t, db, "holidays"), cleanup
+	})
+}
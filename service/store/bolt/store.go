@@ -0,0 +1,258 @@
+package bolt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"go.etcd.io/bbolt"
+)
+
+// Store is a persistent storage on top of bbolt. Every write is a single
+// transaction, so Set, ReplaceYear and Restore are atomic and survive restarts.
+//
+// Holidays are kept in the root bucket, which contains a nested bucket per
+// loaded year. Holidays are keyed by "2006-01-02" dates, so keys are sorted
+// by date. Several stores may share a database with different root buckets.
+type Store struct {
+	db     *bbolt.DB
+	bucket []byte
+}
+
+// check if Store implements Store interface
+var _ store.Store = &Store{}
+
+// New returns store, which keeps holidays in the root bucket of the opened
+// database. The database is owned by the caller and must be closed after use.
+func New(db *bbolt.DB, bucket string) (*Store, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket name is empty")
+	}
+
+	s := &Store{db: db, bucket: []byte(bucket)}
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(s.bucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create bucket '%s': %s", bucket, err)
+	}
+
+	return s, nil
+}
+
+// Set set's holidays to the storage
+func (s *Store) Set(holidays model.Holidays) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return put(tx.Bucket(s.bucket), holidays)
+	})
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	if err := store.CheckYear(year, holidays); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		years := tx.Bucket(s.bucket)
+		if years.Bucket(yearKey(year)) != nil {
+			if err := years.DeleteBucket(yearKey(year)); err != nil {
+				return err
+			}
+		}
+		if _, err := years.CreateBucket(yearKey(year)); err != nil {
+			return err
+		}
+		return put(years, holidays)
+	})
+}
+
+// Get finds holiday by date and returns it.
+// If nothing found - returned bool value is false
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	var (
+		holiday model.Holiday
+		found   bool
+	)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		year := tx.Bucket(s.bucket).Bucket(yearKey(date.Year))
+		if year == nil {
+			return &store.YearNotLoadedError{Year: date.Year}
+		}
+
+		value := year.Get(dateKey(date))
+		if value == nil {
+			return nil
+		}
+		found = true
+		return decode(value, &holiday)
+	})
+	if err != nil {
+		return model.Holiday{}, false, err
+	}
+
+	return holiday, found, nil
+}
+
+// GetRange returns holidays between 'from' and 'to' dates.
+// Returns empty slice if no holidays found
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	holidays := model.Holidays{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		years := tx.Bucket(s.bucket)
+		loaded := func(year int) bool {
+			return years.Bucket(yearKey(year)) != nil
+		}
+		if err := store.CheckCoverage(loaded, from, to); err != nil {
+			return err
+		}
+
+		fromKey, toKey := dateKey(from), dateKey(to)
+		for year := from.Year; year <= to.Year; year++ {
+			c := years.Bucket(yearKey(year)).Cursor()
+			for k, v := c.Seek(fromKey); k != nil && string(k) <= string(toKey); k, v = c.Next() {
+				var h model.Holiday
+				if err := decode(v, &h); err != nil {
+					return err
+				}
+				holidays = append(holidays, h)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return holidays, nil
+}
+
+// Dump returns all holidays from store sorted by date. It returns nil, if the
+// database can't be read.
+func (s *Store) Dump() model.Holidays {
+	holidays := model.Holidays{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		years := tx.Bucket(s.bucket)
+		// keys are sorted as strings, years of different length are not
+		for _, year := range loadedYears(years) {
+			err := years.Bucket(yearKey(year)).ForEach(func(k, v []byte) error {
+				var h model.Holiday
+				if err := decode(v, &h); err != nil {
+					return err
+				}
+				holidays = append(holidays, h)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	return holidays
+}
+
+// Each calls fn for all holidays in date order, until fn returns false.
+// Holidays are read by years, fn is called out of transactions, so it may
+// use the store.
+func (s *Store) Each(fn func(h model.Holiday) bool) error {
+	var years []int
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		years = loadedYears(tx.Bucket(s.bucket))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return store.EachYear(s, years, fn)
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket(s.bucket); err != nil {
+			return err
+		}
+		years, err := tx.CreateBucket(s.bucket)
+		if err != nil {
+			return err
+		}
+		return put(years, holidays)
+	})
+}
+
+// Years returns loaded years in ascending order. It returns nil, if the
+// database can't be read.
+func (s *Store) Years() []int {
+	var years []int
+	s.db.View(func(tx *bbolt.Tx) error {
+		years = loadedYears(tx.Bucket(s.bucket))
+		return nil
+	})
+	return years
+}
+
+// put puts holidays to year buckets, creating them if needed
+func put(years *bbolt.Bucket, holidays model.Holidays) error {
+	for _, h := range holidays {
+		year, err := years.CreateBucketIfNotExists(yearKey(h.Date.Year))
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(h)
+		if err != nil {
+			return fmt.Errorf("can't encode holiday %s: %s", h.Date, err)
+		}
+		if err := year.Put(dateKey(h.Date), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decode(value []byte, h *model.Holiday) error {
+	if err := json.Unmarshal(value, h); err != nil {
+		return fmt.Errorf("can't decode holiday: %s", err)
+	}
+	return nil
+}
+
+// loadedYears returns years of nested buckets in ascending order
+func loadedYears(years *bbolt.Bucket) []int {
+	loaded := []int{}
+	years.ForEach(func(k, v []byte) error {
+		// nested buckets have nil values
+		if v != nil {
+			return nil
+		}
+		if year, err := strconv.Atoi(string(k)); err == nil {
+			loaded = append(loaded, year)
+		}
+		return nil
+	})
+	sort.Ints(loaded)
+
+	return loaded
+}
+
+func yearKey(year int) []byte {
+	return []byte(strconv.Itoa(year))
+}
+
+func dateKey(date model.Date) []byte {
+	return []byte(date.String())
+}
@@ -0,0 +1,73 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/storetest"
+	"go.etcd.io/bbolt"
+)
+
+// openDB opens a database in a temporary directory, which is removed by
+// returned cleanup function
+func openDB(t *testing.T) (*bbolt.DB, string, func()) {
+	dir, err := ioutil.TempDir("", "golidays-bolt")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+
+	path := filepath.Join(dir, "holidays.db")
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	return db, path, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func newStore(t *testing.T, db *bbolt.DB, bucket string) *Store {
+	store, err := New(db, bucket)
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	return store
+}
+
+func TestPersistence(t *testing.T) {
+	db, path, cleanup := openDB(t)
+	defer cleanup()
+
+	holidays := storetest.Holidays()
+	if err := newStore(t, db, "holidays").Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	other := model.Holidays{{Date: model.NewDate(2019, 8, 30), Type: model.TypeHoliday}}
+	if err := newStore(t, db, "holidays-RU-TA").Set(other); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	// reopen the database
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+	defer db.Close()
+
+	if dump := newStore(t, db, "holidays").Dump(); !reflect.DeepEqual(dump, holidays) {
+		t.Errorf("stored data %#v != original %#v", dump, holidays)
+	}
+	if dump := newStore(t, db, "holidays-RU-TA").Dump(); !reflect.DeepEqual(dump, other) {
+		t.Errorf("stored data %#v != original %#v", dump, other)
+	}
+}
@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Store is a read-through store decorator, which keeps years in memory, so
+// getters don't hit slow backends, e.g. SQL or Redis. A year is fetched
+// entirely on the first query and kept until the TTL expires or it's
+// changed through the decorator.
+//
+// Writes through the decorator invalidate the affected years, writes made
+// directly to the underlying store are noticed after the TTL only.
+type Store struct {
+	// hits and misses are first to be 64-bit aligned for atomic operations
+	hits, misses uint64
+
+	store.Store
+	ttl time.Duration
+	now func() time.Time
+
+	mu sync.Mutex
+	// years are cached years, entries are never modified
+	years map[int]*entry
+	// loaded is the cached list of loaded years
+	loaded *entry
+	// generation is incremented by invalidations, so fetches started
+	// before don't cache stale data
+	generation uint64
+}
+
+// entry is a cached year or the list of loaded years
+type entry struct {
+	holidays model.Holidays
+	years    []int
+	// ok is false for years, which are not loaded
+	ok      bool
+	expires time.Time
+}
+
+// Stats are cache counters, every year lookup is either a hit or a miss
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// check if Store implements Store interface
+var _ store.Store = &Store{}
+
+// New returns caching decorator of the store, cached data expires after the
+// ttl, it never expires if the ttl is zero
+func New(s store.Store, ttl time.Duration) *Store {
+	return &Store{
+		Store: s,
+		ttl:   ttl,
+		now:   time.Now,
+		years: make(map[int]*entry),
+	}
+}
+
+// Stats returns the cache counters
+func (s *Store) Stats() Stats {
+	return Stats{
+		Hits:   atomic.LoadUint64(&s.hits),
+		Misses: atomic.LoadUint64(&s.misses),
+	}
+}
+
+// Invalidate drops all cached data, e.g. after writes made directly to the
+// underlying store
+func (s *Store) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.years = make(map[int]*entry)
+	s.loaded = nil
+	s.generation++
+}
+
+// Set set's holidays to the underlying store
+func (s *Store) Set(holidays model.Holidays) error {
+	defer s.invalidate(yearsOf(holidays)...)
+	return s.Store.Set(holidays)
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	defer s.invalidate(year)
+	return s.Store.ReplaceYear(year, holidays)
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	defer s.Invalidate()
+	return s.Store.Restore(holidays)
+}
+
+// Get finds holiday by date in the cached year and returns it.
+// If nothing found - returned bool value is false
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	e, err := s.year(date.Year)
+	if err != nil {
+		return model.Holiday{}, false, err
+	}
+	if !e.ok {
+		return model.Holiday{}, false, &store.YearNotLoadedError{Year: date.Year}
+	}
+
+	i := search(e.holidays, date)
+	if i < len(e.holidays) && e.holidays[i].Date == date {
+		return e.holidays[i], true, nil
+	}
+
+	return model.Holiday{}, false, nil
+}
+
+// GetRange returns holidays between 'from' and 'to' dates of cached years
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	years := make([]*entry, 0, to.Year-from.Year+1)
+	for year := from.Year; year <= to.Year; year++ {
+		e, err := s.year(year)
+		if err != nil {
+			return nil, err
+		}
+		if !e.ok {
+			return nil, &store.YearNotLoadedError{Year: year}
+		}
+		years = append(years, e)
+	}
+
+	holidays := model.Holidays{}
+	for _, e := range years {
+		yearHolidays := e.holidays[search(e.holidays, from):search(e.holidays, to.AddDays(1))]
+		holidays = append(holidays, yearHolidays...)
+	}
+
+	return holidays, nil
+}
+
+// Years returns the cached list of loaded years
+func (s *Store) Years() []int {
+	s.mu.Lock()
+	e, generation := s.loaded, s.generation
+	s.mu.Unlock()
+
+	if s.valid(e) {
+		atomic.AddUint64(&s.hits, 1)
+		return append([]int(nil), e.years...)
+	}
+	atomic.AddUint64(&s.misses, 1)
+
+	years := s.Store.Years()
+	s.mu.Lock()
+	if s.generation == generation {
+		s.loaded = &entry{years: years, ok: true, expires: s.expires()}
+	}
+	s.mu.Unlock()
+
+	return append([]int(nil), years...)
+}
+
+// year returns the cached year, fetching it on a miss. Errors other than
+// *store.YearNotLoadedError are not cached.
+func (s *Store) year(year int) (*entry, error) {
+	s.mu.Lock()
+	e, generation := s.years[year], s.generation
+	s.mu.Unlock()
+
+	if s.valid(e) {
+		atomic.AddUint64(&s.hits, 1)
+		return e, nil
+	}
+	atomic.AddUint64(&s.misses, 1)
+
+	holidays, err := s.Store.GetRange(model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31))
+	if err != nil && !store.IsYearNotLoaded(err) {
+		return nil, err
+	}
+	e = &entry{holidays: holidays, ok: err == nil, expires: s.expires()}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.years[year] = e
+	}
+	s.mu.Unlock()
+
+	return e, nil
+}
+
+// invalidate drops the cached years and the list of loaded years, which
+// may be changed by the write as well
+func (s *Store) invalidate(years ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, year := range years {
+		delete(s.years, year)
+	}
+	s.loaded = nil
+	s.generation++
+}
+
+func (s *Store) valid(e *entry) bool {
+	return e != nil && (s.ttl == 0 || s.now().Before(e.expires))
+}
+
+func (s *Store) expires() time.Time {
+	return s.now().Add(s.ttl)
+}
+
+// search returns the index of the first holiday not before the date
+func search(holidays model.Holidays, date model.Date) int {
+	return sort.Search(len(holidays), func(i int) bool {
+		return !holidays[i].Date.Before(date)
+	})
+}
+
+// yearsOf returns the years of holidays
+func yearsOf(holidays model.Holidays) []int {
+	seen := make(map[int]bool)
+	years := []int{}
+	for _, h := range holidays {
+		if !seen[h.Date.Year] {
+			seen[h.Date.Year] = true
+			years = append(years, h.Date.Year)
+		}
+	}
+	return years
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+// counting counts reads of the underlying store
+type counting struct {
+	store.Store
+	reads int
+}
+
+func (c *counting) GetRange(from, to model.Date) (model.Holidays, error) {
+	c.reads++
+	return c.Store.GetRange(from, to)
+}
+
+func (c *counting) Years() []int {
+	c.reads++
+	return c.Store.Years()
+}
+
+// newStore returns cache of storetest.Holidays with a fixed clock and
+// the underlying store
+func newStore(t *testing.T, ttl time.Duration) (*Store, *counting, *time.Time) {
+	underlying := &counting{Store: memory.New()}
+	if err := underlying.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := New(underlying, ttl)
+	s.now = func() time.Time {
+		return now
+	}
+	return s, underlying, &now
+}
+
+func TestGet(t *testing.T) {
+	s, underlying, _ := newStore(t, 0)
+	holidays := storetest.Holidays()
+
+	for i := 0; i < 2; i++ {
+		for _, h := range holidays[2:7] {
+			storedH, ok, err := s.Get(h.Date)
+			if err != nil || !ok || storedH != h {
+				t.Errorf("holiday %#v, %t, %v != expected %#v", storedH, ok, err, h)
+			}
+		}
+	}
+	if _, ok, err := s.Get(model.NewDate(2019, 3, 8)); err != nil || ok {
+		t.Errorf("unexpected holiday of 2019-03-08: %t, %v", ok, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := s.Get(model.NewDate(2021, 1, 1)); !store.IsYearNotLoaded(err) {
+			t.Errorf("unexpected error of not loaded year: %v", err)
+		}
+	}
+
+	if underlying.reads != 2 {
+		t.Errorf("underlying store is read %d times instead of 2", underlying.reads)
+	}
+	if stats := s.Stats(); stats != (Stats{Hits: 11, Misses: 2}) {
+		t.Errorf("stats %#v != expected {Hits: 11, Misses: 2}", stats)
+	}
+}
+
+func TestGetRange(t *testing.T) {
+	s, underlying, _ := newStore(t, 0)
+	holidays := storetest.Holidays()
+
+	for i := 0; i < 2; i++ {
+		got, err := s.GetRange(model.NewDate(2018, 12, 30), model.NewDate(2020, 2, 29))
+		if err != nil {
+			t.Fatalf("GetRange failed: %s", err)
+		}
+		if !reflect.DeepEqual(got, holidays[1:8]) {
+			t.Errorf("holidays %#v != expected %#v", got, holidays[1:8])
+		}
+	}
+
+	if underlying.reads != 3 {
+		t.Errorf("underlying store is read %d times instead of 3", underlying.reads)
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	s, underlying, _ := newStore(t, 0)
+	date := model.NewDate(2019, 2, 22)
+
+	if _, _, err := s.Get(date); err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if years := s.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020}) {
+		t.Errorf("years %v != [2018 2019 2020]", years)
+	}
+
+	holiday := model.Holiday{Date: date, Type: model.TypeHoliday}
+	if err := s.Set(model.Holidays{holiday}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if h, _, err := s.Get(date); err != nil || h != holiday {
+		t.Errorf("holiday %#v, %v != expected %#v after Set", h, err, holiday)
+	}
+
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	if years := s.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020, 2021}) {
+		t.Errorf("years %v != [2018 2019 2020 2021] after ReplaceYear", years)
+	}
+
+	if err := s.Restore(nil); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if _, _, err := s.Get(date); !store.IsYearNotLoaded(err) {
+		t.Errorf("unexpected error after Restore: %v", err)
+	}
+
+	// direct writes need an explicit invalidation
+	if err := underlying.Set(model.Holidays{holiday}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if _, _, err := s.Get(date); !store.IsYearNotLoaded(err) {
+		t.Errorf("unexpected error of cached year: %v", err)
+	}
+	s.Invalidate()
+	if h, _, err := s.Get(date); err != nil || h != holiday {
+		t.Errorf("holiday %#v, %v != expected %#v after Invalidate", h, err, holiday)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	s, underlying, now := newStore(t, time.Hour)
+	date := model.NewDate(2019, 2, 22)
+
+	if _, _, err := s.Get(date); err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	*now = now.Add(time.Hour - time.Nanosecond)
+	if _, _, err := s.Get(date); err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if underlying.reads != 1 {
+		t.Errorf("underlying store is read %d times before expiration instead of 1", underlying.reads)
+	}
+
+	*now = now.Add(time.Nanosecond)
+	if _, _, err := s.Get(date); err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if underlying.reads != 2 {
+		t.Errorf("underlying store is read %d times after expiration instead of 2", underlying.reads)
+	}
+}
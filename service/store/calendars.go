@@ -0,0 +1,39 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/mwf/golidays/model"
+)
+
+// CalendarStore is an interface for a set of stores, a Store per calendar
+type CalendarStore interface {
+	// Calendar returns the store of the calendar.
+	// If nothing found - returned bool value is false
+	Calendar(calendar model.Calendar) (Store, bool)
+	// Calendars returns all known calendars, sorted by name
+	Calendars() []model.Calendar
+}
+
+// Calendars is a simple CalendarStore on top of a map
+type Calendars map[model.Calendar]Store
+
+// check if Calendars implements CalendarStore interface
+var _ CalendarStore = Calendars{}
+
+func (c Calendars) Calendar(calendar model.Calendar) (Store, bool) {
+	s, ok := c[calendar]
+	return s, ok
+}
+
+func (c Calendars) Calendars() []model.Calendar {
+	calendars := make([]model.Calendar, 0, len(c))
+	for calendar := range c {
+		calendars = append(calendars, calendar)
+	}
+	sort.Slice(calendars, func(i, j int) bool {
+		return calendars[i].String() < calendars[j].String()
+	})
+
+	return calendars
+}
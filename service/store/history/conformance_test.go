@@ -0,0 +1,19 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		s, err := New(memory.New(), "test", nil)
+		if err != nil {
+			t.Fatalf("New failed: %s", err)
+		}
+		return s, func() {}
+	})
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mwf/golidays/model"
+)
+
+// Log is a storage of versions. Records are appended in order of versions and
+// never modified.
+type Log interface {
+	// Append appends the record of the next version
+	Append(r Record) error
+	// Records returns all records in order of versions
+	Records() ([]Record, error)
+}
+
+// Record is a version with snapshots of years it has changed, the initial
+// version has snapshots of all loaded years
+type Record struct {
+	Version
+	Years []YearSnapshot `json:"years"`
+}
+
+// YearSnapshot is the data of the year after the change
+type YearSnapshot struct {
+	Year int `json:"year"`
+	// Loaded is false, if the year is purged by the change
+	Loaded   bool           `json:"loaded"`
+	Holidays model.Holidays `json:"holidays"`
+}
+
+// memoryLog keeps records in memory, they are lost on restart
+type memoryLog struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewMemoryLog returns the log, which keeps records in memory, they are lost
+// on restart
+func NewMemoryLog() Log {
+	return &memoryLog{}
+}
+
+func (l *memoryLog) Append(r Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, r)
+	return nil
+}
+
+func (l *memoryLog) Records() ([]Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Record(nil), l.records...), nil
+}
+
+// FileLog keeps records in the file as JSON values, one per line. Every
+// record is synced to the disk before Append returns.
+type FileLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// check if FileLog implements Log interface
+var _ Log = &FileLog{}
+
+// NewFileLog returns the log in the file, it's created on the first Append
+func NewFileLog(path string) *FileLog {
+	return &FileLog{path: path}
+}
+
+// Append appends the record to the file
+func (l *FileLog) Append(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error encoding version %d: %s", r.ID, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening history log: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing version %d: %s", r.ID, err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("error syncing version %d: %s", r.ID, err)
+	}
+	return f.Close()
+}
+
+// Records reads all records from the file, there are none if the file
+// doesn't exist
+func (l *FileLog) Records() ([]Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history log: %s", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	dec := json.NewDecoder(f)
+	for {
+		var r Record
+		err := dec.Decode(&r)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding version %d: %s", len(records), err)
+		}
+		if r.ID != len(records) {
+			return nil, fmt.Errorf("version %d is recorded instead of %d", r.ID, len(records))
+		}
+		records = append(records, r)
+	}
+}
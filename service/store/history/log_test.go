@@ -0,0 +1,80 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestFileLog_restart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golidays-history")
+	if err != nil {
+		t.Fatalf("TempDir failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history.jsonl")
+
+	s, err := New(memory.New(), "updater", NewFileLog(path))
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	if err := s.WithSource("backup").Restore(storetest.Holidays()); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if err := s.ReplaceYear(2020, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	versions := s.Versions()
+
+	// the in-memory data is lost on restart, the history is not
+	restarted, err := New(memory.New(), "updater", NewFileLog(path))
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	restored := restarted.Versions()
+	if len(restored) != len(versions) {
+		t.Fatalf("got %d versions instead of %d", len(restored), len(versions))
+	}
+	for i, v := range restored {
+		if v.ID != versions[i].ID || v.Source != versions[i].Source || !v.Time.Equal(versions[i].Time) ||
+			!reflect.DeepEqual(v.Changes, versions[i].Changes) {
+			t.Errorf("version %#v != recorded %#v", v, versions[i])
+		}
+	}
+
+	getter, err := restarted.AsOf(1)
+	if err != nil {
+		t.Fatalf("AsOf failed: %s", err)
+	}
+	holidays, err := getter.GetRange(model.NewDate(2018, 1, 1), model.NewDate(2020, 12, 31))
+	if err != nil {
+		t.Fatalf("GetRange failed: %s", err)
+	}
+	if !reflect.DeepEqual(holidays, storetest.Holidays()) {
+		t.Errorf("holidays of version 1 %#v != expected %#v", holidays, storetest.Holidays())
+	}
+
+	// restoring the data of the last version is not a change
+	if err := restarted.Rollback(restarted.Current(), "backup"); err != nil {
+		t.Fatalf("Rollback failed: %s", err)
+	}
+	if n := len(restarted.Versions()); n != len(versions) {
+		t.Errorf("got %d versions after restoring the last one instead of %d", n, len(versions))
+	}
+	if years := restarted.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020}) {
+		t.Errorf("years %v != [2018 2019 2020]", years)
+	}
+}
+
+func TestFileLog_noFile(t *testing.T) {
+	records, err := NewFileLog(filepath.Join(os.TempDir(), "golidays-history-missing.jsonl")).Records()
+	if err != nil || len(records) != 0 {
+		t.Errorf("unexpected records %#v of missing log, error %v", records, err)
+	}
+}
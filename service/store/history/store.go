@@ -0,0 +1,408 @@
+// Package history keeps versions of store data. Every version is recorded
+// with snapshots of years it has changed to a Log, e.g. FileLog, which
+// survives restarts.
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/notify"
+)
+
+// Version is an effective change of the store data. Version 0 is the data
+// at the moment the history is started.
+type Version struct {
+	ID int `json:"id"`
+	// Time is the time of the change
+	Time time.Time `json:"time"`
+	// Source describes the writer, e.g. "updater" or "backup"
+	Source  string       `json:"source"`
+	Changes notify.Event `json:"changes"`
+}
+
+// Store is a store decorator, which keeps the history of changes. Every
+// write, which changes holidays or loaded years, creates a new version.
+// The data of any version can be queried or restored with Rollback.
+//
+// Versions are recorded to the log with snapshots of changed years, so past
+// versions don't depend on the current data. Writes made bypassing the
+// decorator, e.g. by other replicas of a shared store, are recorded with the
+// next write of their years.
+type Store struct {
+	store.Store
+	source string
+	now    func() time.Time
+	log    Log
+
+	// mu serializes writes and history reads
+	mu      sync.Mutex
+	records []Record
+	// latest are loaded years of the last version
+	latest map[int]YearSnapshot
+}
+
+// check if Store implements Store and HolidayQuerier interfaces
+var (
+	_ store.Store          = &Store{}
+	_ store.HolidayQuerier = &Store{}
+)
+
+// New returns history of the store, writes are attributed to the source,
+// unless they are made with WithSource. Versions are read from the log,
+// the initial version is recorded if the log is empty. Versions are kept in
+// memory only, if the log is nil.
+func New(s store.Store, source string, log Log) (*Store, error) {
+	if log == nil {
+		log = NewMemoryLog()
+	}
+
+	h := &Store{
+		Store:  s,
+		source: source,
+		now:    time.Now,
+		log:    log,
+		latest: make(map[int]YearSnapshot),
+	}
+
+	records, err := log.Records()
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %s", err)
+	}
+	for _, r := range records {
+		h.apply(r)
+	}
+	if len(records) > 0 {
+		return h, nil
+	}
+
+	snapshots, err := h.snapshot(s.Years())
+	if err != nil {
+		return nil, fmt.Errorf("error reading initial data: %s", err)
+	}
+	initial := Record{
+		Version: Version{Time: h.now()},
+		Years:   snapshots,
+	}
+	if err := log.Append(initial); err != nil {
+		return nil, fmt.Errorf("error recording initial version: %s", err)
+	}
+	h.apply(initial)
+
+	return h, nil
+}
+
+// WithSource returns the store, which attributes writes to the source
+func (s *Store) WithSource(source string) store.Store {
+	return &sourced{Store: s, source: source}
+}
+
+// Set set's holidays to the underlying store
+func (s *Store) Set(holidays model.Holidays) error {
+	return s.set(s.source, holidays)
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	return s.replaceYear(s.source, year, holidays)
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	return s.restore(s.source, holidays)
+}
+
+func (s *Store) set(source string, holidays model.Holidays) error {
+	return s.write(source, yearsOf(holidays), func() error {
+		return s.Store.Set(holidays)
+	})
+}
+
+func (s *Store) replaceYear(source string, year int, holidays model.Holidays) error {
+	return s.write(source, []int{year}, func() error {
+		return s.Store.ReplaceYear(year, holidays)
+	})
+}
+
+func (s *Store) restore(source string, holidays model.Holidays) error {
+	return s.write(source, nil, func() error {
+		return s.Store.Restore(holidays)
+	})
+}
+
+// NextHoliday returns the first holiday of the types after the date,
+// queries are answered by the current data
+func (s *Store) NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.NextHoliday(s.Store, date, types...)
+}
+
+// PrevHoliday returns the last holiday of the types before the date
+func (s *Store) PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.PrevHoliday(s.Store, date, types...)
+}
+
+// GetRangeByType returns holidays of the types between 'from' and 'to' dates
+func (s *Store) GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	return store.GetRangeByType(s.Store, from, to, types...)
+}
+
+// CountByType returns numbers of holidays between 'from' and 'to' dates by
+// their types
+func (s *Store) CountByType(from, to model.Date) (map[model.HolidayType]int, error) {
+	return store.CountByType(s.Store, from, to)
+}
+
+// Versions returns all versions except the initial one, in order of changes
+func (s *Store) Versions() []Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := make([]Version, 0, len(s.records)-1)
+	for _, r := range s.records[1:] {
+		versions = append(versions, r.Version)
+	}
+	return versions
+}
+
+// Current returns the current version ID
+func (s *Store) Current() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.records) - 1
+}
+
+// VersionAt returns the ID of the version, which was current at the time
+func (s *Store) VersionAt(t time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the first version made after t
+	versions := s.records[1:]
+	return sort.Search(len(versions), func(i int) bool {
+		return versions[i].Time.After(t)
+	})
+}
+
+// AsOf returns the read-only data of the version
+func (s *Store) AsOf(id int) (store.HolidayGetter, error) {
+	s.mu.Lock()
+	holidays, years, err := s.dataOf(id)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	getter := memory.New()
+	if err := load(getter, holidays, years); err != nil {
+		return nil, err
+	}
+	return getter, nil
+}
+
+// AsOfTime returns the read-only data, which was current at the time
+func (s *Store) AsOfTime(t time.Time) (store.HolidayGetter, error) {
+	return s.AsOf(s.VersionAt(t))
+}
+
+// Rollback restores the data of the version, creating a new version
+func (s *Store) Rollback(id int, source string) error {
+	s.mu.Lock()
+	holidays, years, err := s.dataOf(id)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return s.write(source, nil, func() error {
+		return load(s.Store, holidays, years)
+	})
+}
+
+// write performs the write and records a new version with snapshots of
+// the years, if they are changed since the last version. All years of the
+// last version and the store are snapshotted, if years are nil.
+//
+// The data is already written, if the version can't be recorded.
+func (s *Store) write(source string, years []int, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if years == nil {
+		years = s.Store.Years()
+		for year := range s.latest {
+			years = append(years, year)
+		}
+	}
+	snapshots, err := s.snapshot(years)
+	if err != nil {
+		return fmt.Errorf("can't record version: %s", err)
+	}
+
+	var before, after model.Holidays
+	changed := make([]YearSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		last, ok := s.latest[snapshot.Year]
+		if ok == snapshot.Loaded && reflect.DeepEqual(last.Holidays, snapshot.Holidays) {
+			continue
+		}
+		before = append(before, last.Holidays...)
+		after = append(after, snapshot.Holidays...)
+		changed = append(changed, snapshot)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	r := Record{
+		Version: Version{
+			ID:      len(s.records),
+			Time:    s.now(),
+			Source:  source,
+			Changes: notify.Diff(before, after),
+		},
+		Years: changed,
+	}
+	if err := s.log.Append(r); err != nil {
+		return fmt.Errorf("can't record version %d: %s", r.ID, err)
+	}
+	s.apply(r)
+
+	return nil
+}
+
+// snapshot returns sorted snapshots of the years of the current data
+func (s *Store) snapshot(years []int) ([]YearSnapshot, error) {
+	seen := make(map[int]bool, len(years))
+	snapshots := make([]YearSnapshot, 0, len(years))
+	for _, year := range years {
+		if seen[year] {
+			continue
+		}
+		seen[year] = true
+
+		holidays, err := s.Store.GetRange(model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31))
+		if store.IsYearNotLoaded(err) {
+			snapshots = append(snapshots, YearSnapshot{Year: year})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if holidays == nil {
+			holidays = model.Holidays{}
+		}
+		snapshots = append(snapshots, YearSnapshot{Year: year, Loaded: true, Holidays: holidays})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Year < snapshots[j].Year
+	})
+	return snapshots, nil
+}
+
+// apply appends the record and updates years of the last version
+func (s *Store) apply(r Record) {
+	s.records = append(s.records, r)
+	applySnapshots(s.latest, r.Years)
+}
+
+// dataOf returns holidays and loaded years of the version, built from
+// snapshots of it and previous versions. Must be called under lock.
+func (s *Store) dataOf(id int) (model.Holidays, []int, error) {
+	if id < 0 || id >= len(s.records) {
+		return nil, nil, fmt.Errorf("unknown version %d", id)
+	}
+
+	state := make(map[int]YearSnapshot)
+	for _, r := range s.records[:id+1] {
+		applySnapshots(state, r.Years)
+	}
+
+	years := make([]int, 0, len(state))
+	for year := range state {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	holidays := model.Holidays{}
+	for _, year := range years {
+		holidays = append(holidays, state[year].Holidays...)
+	}
+
+	return holidays, years, nil
+}
+
+// applySnapshots updates loaded years with snapshots
+func applySnapshots(years map[int]YearSnapshot, snapshots []YearSnapshot) {
+	for _, snapshot := range snapshots {
+		if snapshot.Loaded {
+			years[snapshot.Year] = snapshot
+		} else {
+			delete(years, snapshot.Year)
+		}
+	}
+}
+
+// load restores holidays and loaded years to the store
+func load(s store.Store, holidays model.Holidays, years []int) error {
+	if err := s.Restore(holidays); err != nil {
+		return err
+	}
+
+	// years without holidays
+	loaded := make(map[int]bool)
+	for _, year := range s.Years() {
+		loaded[year] = true
+	}
+	for _, year := range years {
+		if loaded[year] {
+			continue
+		}
+		if err := s.ReplaceYear(year, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yearsOf returns the years of holidays
+func yearsOf(holidays model.Holidays) []int {
+	seen := make(map[int]bool)
+	years := []int{}
+	for _, h := range holidays {
+		if !seen[h.Date.Year] {
+			seen[h.Date.Year] = true
+			years = append(years, h.Date.Year)
+		}
+	}
+	return years
+}
+
+// sourced attributes writes to its source
+type sourced struct {
+	*Store
+	source string
+}
+
+func (s *sourced) Set(holidays model.Holidays) error {
+	return s.set(s.source, holidays)
+}
+
+func (s *sourced) ReplaceYear(year int, holidays model.Holidays) error {
+	return s.replaceYear(s.source, year, holidays)
+}
+
+func (s *sourced) Restore(holidays model.Holidays) error {
+	return s.restore(s.source, holidays)
+}
@@ -0,0 +1,282 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+var start = time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newStore returns history with a clock, ticking an hour on every change.
+// Versions are:
+//
+//	1: storetest.Holidays() by "backup"
+//	2: 2019-05-09 as a holiday by "updater"
+//	3: 2019-02-22 retyped to a holiday by "decree"
+func newStore(t *testing.T) *Store {
+	s, err := New(memory.New(), "updater", nil)
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	ticks := 0
+	s.now = func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * time.Hour)
+	}
+
+	if err := s.WithSource("backup").Restore(storetest.Holidays()); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if err := s.Set(model.Holidays{{Date: model.NewDate(2019, 5, 9), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := s.WithSource("decree").Set(model.Holidays{{Date: model.NewDate(2019, 2, 22), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	return s
+}
+
+func TestVersions(t *testing.T) {
+	s := newStore(t)
+
+	// no-op writes create no versions
+	if err := s.Set(model.Holidays{{Date: model.NewDate(2019, 5, 9), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	// loaded years are the data as well
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	versions := s.Versions()
+	if len(versions) != 4 || s.Current() != 4 {
+		t.Fatalf("got %d versions, current %d, instead of 4: %#v", len(versions), s.Current(), versions)
+	}
+
+	sources := []string{"backup", "updater", "decree", "updater"}
+	for i, v := range versions {
+		if v.ID != i+1 {
+			t.Errorf("version %d ID %d != %d", i, v.ID, i+1)
+		}
+		if v.Source != sources[i] {
+			t.Errorf("version %d source %q != %q", v.ID, v.Source, sources[i])
+		}
+		if expected := start.Add(time.Duration(i+1) * time.Hour); !v.Time.Equal(expected) {
+			t.Errorf("version %d time %s != %s", v.ID, v.Time, expected)
+		}
+	}
+
+	retyped := versions[2].Changes.Retyped
+	if len(retyped) != 1 || retyped[0].Old.Type != model.TypePreholiday || retyped[0].New.Type != model.TypeHoliday {
+		t.Errorf("unexpected changes of version 3: %#v", versions[2].Changes)
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	s := newStore(t)
+	date := model.NewDate(2019, 2, 22)
+
+	types := map[int]model.HolidayType{
+		1: model.TypePreholiday,
+		2: model.TypePreholiday,
+		3: model.TypeHoliday,
+	}
+	for id, typ := range types {
+		getter, err := s.AsOf(id)
+		if err != nil {
+			t.Fatalf("AsOf failed: %s", err)
+		}
+		h, ok, err := getter.Get(date)
+		if err != nil || !ok || h.Type != typ {
+			t.Errorf("holiday %s of version %d: %#v, %t, %v, expected type %s", date, id, h, ok, err, typ)
+		}
+	}
+
+	// the initial version is empty
+	getter, err := s.AsOf(0)
+	if err != nil {
+		t.Fatalf("AsOf failed: %s", err)
+	}
+	if _, _, err := getter.Get(date); !store.IsYearNotLoaded(err) {
+		t.Errorf("unexpected error of version 0: %v", err)
+	}
+
+	// 2019-05-09 is added by version 2
+	getter, err = s.AsOf(1)
+	if err != nil {
+		t.Fatalf("AsOf failed: %s", err)
+	}
+	holidays, err := getter.GetRange(model.NewDate(2019, 5, 1), model.NewDate(2019, 5, 31))
+	if err != nil {
+		t.Fatalf("GetRange failed: %s", err)
+	}
+	if !reflect.DeepEqual(holidays, storetest.Holidays()[6:7]) {
+		t.Errorf("holidays of version 1 %#v != expected %#v", holidays, storetest.Holidays()[6:7])
+	}
+
+	if _, err := s.AsOf(4); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+}
+
+func TestAsOfTime(t *testing.T) {
+	s := newStore(t)
+
+	times := map[time.Time]int{
+		start:                                    0,
+		start.Add(time.Hour):                     1,
+		start.Add(2*time.Hour + 30*time.Minute):  2,
+		start.Add(3 * time.Hour):                 3,
+		start.Add(24 * time.Hour):                3,
+		start.Add(-24 * time.Hour):               0,
+		start.Add(2*time.Hour - time.Nanosecond): 1,
+	}
+	for tm, id := range times {
+		if v := s.VersionAt(tm); v != id {
+			t.Errorf("version at %s %d != %d", tm, v, id)
+		}
+	}
+
+	getter, err := s.AsOfTime(start.Add(90 * time.Minute))
+	if err != nil {
+		t.Fatalf("AsOfTime failed: %s", err)
+	}
+	if _, ok, _ := getter.Get(model.NewDate(2019, 5, 9)); ok {
+		t.Errorf("holiday of version 2 found at version 1")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	s := newStore(t)
+
+	if err := s.Rollback(1, "rollback"); err != nil {
+		t.Fatalf("Rollback failed: %s", err)
+	}
+	if dump := s.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("data %#v != version 1 %#v", dump, storetest.Holidays())
+	}
+
+	versions := s.Versions()
+	if len(versions) != 4 || versions[3].Source != "rollback" {
+		t.Fatalf("rollback version is not created: %#v", versions)
+	}
+	if changes := versions[3].Changes; len(changes.Removed) != 1 || len(changes.Retyped) != 1 {
+		t.Errorf("unexpected changes of rollback: %#v", changes)
+	}
+
+	// loaded years are rolled back as well
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	if err := s.Restore(nil); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if err := s.Rollback(5, "rollback"); err != nil {
+		t.Fatalf("Rollback failed: %s", err)
+	}
+	if years := s.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020, 2021}) {
+		t.Errorf("years %v != [2018 2019 2020 2021]", years)
+	}
+}
+
+// dumpCounter counts full reads of the store
+type dumpCounter struct {
+	store.Store
+	dumps int
+}
+
+func (s *dumpCounter) Dump() model.Holidays {
+	s.dumps++
+	return s.Store.Dump()
+}
+
+func (s *dumpCounter) Each(fn func(h model.Holiday) bool) error {
+	s.dumps++
+	return s.Store.Each(fn)
+}
+
+func TestWrite_affectedYears(t *testing.T) {
+	counter := &dumpCounter{Store: memory.New()}
+	s, err := New(counter, "updater", nil)
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	if err := s.Restore(storetest.Holidays()); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	counter.dumps = 0
+
+	if err := s.Set(model.Holidays{{Date: model.NewDate(2019, 5, 9), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := s.ReplaceYear(2020, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	if counter.dumps != 0 {
+		t.Errorf("writes of years read the whole data %d times", counter.dumps)
+	}
+
+	versions := s.Versions()
+	if len(versions) != 3 {
+		t.Fatalf("got %d versions instead of 3: %#v", len(versions), versions)
+	}
+	if removed := versions[2].Changes.Removed; len(removed) != 2 || removed[0].Date.Year != 2020 {
+		t.Errorf("unexpected changes of ReplaceYear: %#v", versions[2].Changes)
+	}
+}
+
+func TestAsOf_writesBypassing(t *testing.T) {
+	underlying := memory.New()
+	s, err := New(underlying, "updater", nil)
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+
+	mayDay := model.Holiday{Date: model.NewDate(2019, 5, 1), Type: model.TypeHoliday}
+	if err := s.Set(model.Holidays{mayDay}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	// e.g. another replica of a shared store
+	russiaDay := model.Holiday{Date: model.NewDate(2019, 6, 12), Type: model.TypeHoliday}
+	if err := underlying.Set(model.Holidays{russiaDay}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	getter, err := s.AsOf(0)
+	if err != nil {
+		t.Fatalf("AsOf failed: %s", err)
+	}
+	if years := getter.Years(); len(years) != 0 {
+		t.Errorf("years %v of the initial version should be empty", years)
+	}
+
+	getter, err = s.AsOf(1)
+	if err != nil {
+		t.Fatalf("AsOf failed: %s", err)
+	}
+	holidays, err := getter.GetRange(model.NewDate(2019, 1, 1), model.NewDate(2019, 12, 31))
+	if err != nil {
+		t.Fatalf("GetRange failed: %s", err)
+	}
+	if expected := (model.Holidays{mayDay}); !reflect.DeepEqual(holidays, expected) {
+		t.Errorf("holidays of version 1 %#v != expected %#v", holidays, expected)
+	}
+
+	// the bypassing write is recorded with the next write of the year
+	newYear := model.Holiday{Date: model.NewDate(2019, 1, 1), Type: model.TypeHoliday}
+	if err := s.Set(model.Holidays{newYear}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	versions := s.Versions()
+	if expected := (model.Holidays{newYear, russiaDay}); len(versions) != 2 || !reflect.DeepEqual(versions[1].Changes.Added, expected) {
+		t.Errorf("unexpected versions %#v, expected %#v added by version 2", versions, expected)
+	}
+}
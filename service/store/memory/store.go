@@ -2,26 +2,42 @@ package memory
 
 import (
 	"fmt"
+	"sort"
 	"sync"
-	"time"
+	"sync/atomic"
 
 	"github.com/mwf/golidays/model"
 	"github.com/mwf/golidays/service/store"
 )
 
-// Store is a simple in-memory storage
+// Store is a simple in-memory storage. Holidays are indexed by years, every
+// year is a slice sorted by date, so lookups are binary searches.
+//
+// Data is published as immutable snapshots: writers copy the changed years
+// and atomically swap the snapshot, so readers never block and never see
+// a partial update. Stored holidays are copied and don't share memory with
+// the caller.
 type Store struct {
-	byDate map[time.Time]*model.Holiday
-	mu     sync.RWMutex
+	// snapshot holds the current years
+	snapshot atomic.Value
+	// mu serializes writers
+	mu sync.Mutex
 }
 
-// check if Store implements Store interface
-var _ store.Store = New()
+// years contains loaded years, even without holidays. It must not be
+// modified once published.
+type years map[int]model.Holidays
+
+// check if Store implements Store and HolidayQuerier interfaces
+var (
+	_ store.Store          = New()
+	_ store.HolidayQuerier = New()
+)
 
 func New() *Store {
-	return &Store{
-		byDate: make(map[time.Time]*model.Holiday),
-	}
+	s := &Store{}
+	s.snapshot.Store(years{})
+	return s
 }
 
 // Set set's holidays to in-memory storage
@@ -29,82 +45,315 @@ func (s *Store) Set(holidays model.Holidays) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, holiday := range holidays {
-		s.byDate[holiday.Date] = &holidays[i]
+	current := s.load()
+	next := current.clone()
+	for year, yearHolidays := range byYear(holidays) {
+		next[year] = upsert(current[year], yearHolidays)
 	}
+	s.snapshot.Store(next)
+
+	return nil
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	if err := store.CheckYear(year, holidays); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.load().clone()
+	next[year] = upsert(nil, holidays)
+	s.snapshot.Store(next)
 
 	return nil
 }
 
 // Get finds holiday by date and returns it.
 // If nothing found - returned bool value is false
-func (s *Store) Get(day time.Time) (model.Holiday, bool, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	holidays, ok := s.load()[date.Year]
+	if !ok {
+		return model.Holiday{}, false, &store.YearNotLoadedError{Year: date.Year}
+	}
 
-	date := model.NewDay(day.Date())
-	if holiday, ok := s.byDate[date]; ok {
-		return *holiday, ok, nil
+	i := search(holidays, date)
+	if i < len(holidays) && holidays[i].Date == date {
+		return holidays[i], true, nil
 	}
 
 	return model.Holiday{}, false, nil
 }
 
-// GetRange returns holidays between 'from' and 'to' dates
-func (s *Store) GetRange(from, to time.Time) (model.Holidays, error) {
-	dateFrom := model.NewDay(from.Date())
-	dateTo := model.NewDay(to.Date())
+// GetRange returns holidays between 'from' and 'to' dates.
+// Returns empty slice if no holidays found
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	snapshot := s.load()
+	if err := store.CheckCoverage(snapshot.loaded, from, to); err != nil {
+		return nil, err
+	}
 
-	return s.getRangeNaive(dateFrom, dateTo)
+	// find bounds in the first and the last years, all years in between
+	// are taken entirely
+	first, last := snapshot[from.Year], snapshot[to.Year]
+	i, j := search(first, from), search(last, to.AddDays(1))
+
+	size := 0
+	for year := from.Year; year <= to.Year; year++ {
+		size += len(snapshot[year])
+	}
+	size -= i + len(last) - j
+
+	holidays := make(model.Holidays, 0, size)
+	for year := from.Year; year <= to.Year; year++ {
+		yearHolidays := snapshot[year]
+		if year == to.Year {
+			yearHolidays = yearHolidays[:j]
+		}
+		if year == from.Year {
+			yearHolidays = yearHolidays[i:]
+		}
+		holidays = append(holidays, yearHolidays...)
+	}
+
+	return holidays, nil
 }
 
-// Naive implementation - simply range over all dates in range
-// Returns empty slice if no holidays found
-func (s *Store) getRangeNaive(from, to time.Time) (model.Holidays, error) {
+// NextHoliday returns the first holiday of the types after the date
+func (s *Store) NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	snapshot, match := s.load(), store.MatchTypes(types...)
+
+	from := date.AddDays(1)
+	for year := from.Year; ; year++ {
+		holidays, ok := snapshot[year]
+		if !ok {
+			return model.Holiday{}, &store.YearNotLoadedError{Year: year}
+		}
+
+		i := 0
+		if year == from.Year {
+			i = search(holidays, from)
+		}
+		for ; i < len(holidays); i++ {
+			if match(holidays[i].Type) {
+				return holidays[i], nil
+			}
+		}
+	}
+}
+
+// PrevHoliday returns the last holiday of the types before the date
+func (s *Store) PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	snapshot, match := s.load(), store.MatchTypes(types...)
+
+	to := date.AddDays(-1)
+	for year := to.Year; ; year-- {
+		holidays, ok := snapshot[year]
+		if !ok {
+			return model.Holiday{}, &store.YearNotLoadedError{Year: year}
+		}
+
+		i := len(holidays)
+		if year == to.Year {
+			i = search(holidays, date)
+		}
+		for i--; i >= 0; i-- {
+			if match(holidays[i].Type) {
+				return holidays[i], nil
+			}
+		}
+	}
+}
+
+// GetRangeByType returns holidays of the types between 'from' and 'to' dates
+func (s *Store) GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	if len(types) == 0 {
+		return s.GetRange(from, to)
+	}
 	if to.Before(from) {
 		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
 	}
 
-	holidays := model.Holidays{}
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	snapshot, match := s.load(), store.MatchTypes(types...)
+	if err := store.CheckCoverage(snapshot.loaded, from, to); err != nil {
+		return nil, err
+	}
 
-	for from.Before(to) {
-		if h, ok := s.byDate[from]; ok {
-			holidays = append(holidays, *h)
+	holidays := model.Holidays{}
+	for year := from.Year; year <= to.Year; year++ {
+		for _, h := range snapshot.within(year, from, to) {
+			if match(h.Type) {
+				holidays = append(holidays, h)
+			}
 		}
-		from = from.Add(24 * time.Hour)
-	}
-	// check the last day
-	if h, ok := s.byDate[to]; ok {
-		holidays = append(holidays, *h)
 	}
 
 	return holidays, nil
 }
 
-// Dump returns all holidays from store in random order
+// CountByType returns numbers of holidays between 'from' and 'to' dates by
+// their types
+func (s *Store) CountByType(from, to model.Date) (map[model.HolidayType]int, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	snapshot := s.load()
+	if err := store.CheckCoverage(snapshot.loaded, from, to); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[model.HolidayType]int)
+	for year := from.Year; year <= to.Year; year++ {
+		for _, h := range snapshot.within(year, from, to) {
+			counts[h.Type]++
+		}
+	}
+
+	return counts, nil
+}
+
+// Dump returns all holidays from store sorted by date
 func (s *Store) Dump() model.Holidays {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	snapshot := s.load()
 
-	holidays := make(model.Holidays, 0, len(s.byDate))
-	for _, h := range s.byDate {
-		holidays = append(holidays, *h)
+	holidays := model.Holidays{}
+	for _, year := range snapshot.sorted() {
+		holidays = append(holidays, snapshot[year]...)
 	}
 
 	return holidays
 }
 
+// Each calls fn for all holidays of the current snapshot in date order,
+// until fn returns false
+func (s *Store) Each(fn func(h model.Holiday) bool) error {
+	snapshot := s.load()
+	for _, year := range snapshot.sorted() {
+		for _, h := range snapshot[year] {
+			if !fn(h) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
 // Restore purges all items and sets provided
 func (s *Store) Restore(holidays model.Holidays) error {
+	next := years{}
+	for year, yearHolidays := range byYear(holidays) {
+		next[year] = upsert(nil, yearHolidays)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.byDate = make(map[time.Time]*model.Holiday)
-	for i, holiday := range holidays {
-		s.byDate[holiday.Date] = &holidays[i]
+	s.snapshot.Store(next)
+	return nil
+}
+
+// Years returns loaded years in ascending order
+func (s *Store) Years() []int {
+	return s.load().sorted()
+}
+
+// load returns the current snapshot
+func (s *Store) load() years {
+	return s.snapshot.Load().(years)
+}
+
+// clone returns a copy of the snapshot to be modified by a writer. Years are
+// shared, writers replace them, but never modify.
+func (y years) clone() years {
+	cloned := make(years, len(y)+1)
+	for year, holidays := range y {
+		cloned[year] = holidays
 	}
+	return cloned
+}
 
-	return nil
+// loaded reports if the year is loaded
+func (y years) loaded(year int) bool {
+	_, ok := y[year]
+	return ok
+}
+
+// within returns holidays of the year between 'from' and 'to' dates, the
+// slice is shared with the snapshot
+func (y years) within(year int, from, to model.Date) model.Holidays {
+	holidays := y[year]
+
+	i, j := 0, len(holidays)
+	if year == from.Year {
+		i = search(holidays, from)
+	}
+	if year == to.Year {
+		j = search(holidays, to.AddDays(1))
+	}
+	return holidays[i:j]
+}
+
+// sorted returns loaded years in ascending order
+func (y years) sorted() []int {
+	sorted := make([]int, 0, len(y))
+	for year := range y {
+		sorted = append(sorted, year)
+	}
+	sort.Ints(sorted)
+
+	return sorted
+}
+
+// search returns the index of the first holiday not before the date
+func search(holidays model.Holidays, date model.Date) int {
+	return sort.Search(len(holidays), func(i int) bool {
+		return !holidays[i].Date.Before(date)
+	})
+}
+
+// byYear groups holidays by years, keeping the order
+func byYear(holidays model.Holidays) map[int]model.Holidays {
+	years := make(map[int]model.Holidays)
+	for _, h := range holidays {
+		years[h.Date.Year] = append(years[h.Date.Year], h)
+	}
+	return years
+}
+
+// upsert returns a new sorted slice of sorted 'holidays' with 'updates' set,
+// neither of them is modified. If 'updates' contain the same date several
+// times, the last one wins.
+func upsert(holidays, updates model.Holidays) model.Holidays {
+	sorted := make(model.Holidays, len(updates))
+	copy(sorted, updates)
+	sort.Stable(model.HolidaysByDate(sorted))
+
+	merged := make(model.Holidays, 0, len(holidays)+len(sorted))
+	i, j := 0, 0
+	for i < len(holidays) || j < len(sorted) {
+		switch {
+		case j == len(sorted) || (i < len(holidays) && holidays[i].Date.Before(sorted[j].Date)):
+			merged = append(merged, holidays[i])
+			i++
+		default:
+			if i < len(holidays) && holidays[i].Date == sorted[j].Date {
+				i++
+			}
+			// skip duplicates in updates, the last one wins
+			for j+1 < len(sorted) && sorted[j+1].Date == sorted[j].Date {
+				j++
+			}
+			merged = append(merged, sorted[j])
+			j++
+		}
+	}
+
+	return merged
 }
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// naiveStore is the previous implementation: a map keyed by date and a range
+// walk over every day, kept to compare with
+type naiveStore struct {
+	byDate map[model.Date]*model.Holiday
+}
+
+func newNaiveStore(holidays model.Holidays) *naiveStore {
+	s := &naiveStore{byDate: make(map[model.Date]*model.Holiday)}
+	for i, h := range holidays {
+		s.byDate[h.Date] = &holidays[i]
+	}
+	return s
+}
+
+func (s *naiveStore) Get(date model.Date) (model.Holiday, bool, error) {
+	if h, ok := s.byDate[date]; ok {
+		return *h, true, nil
+	}
+	return model.Holiday{}, false, nil
+}
+
+func (s *naiveStore) GetRange(from, to model.Date) (model.Holidays, error) {
+	holidays := model.Holidays{}
+	for !to.Before(from) {
+		if h, ok := s.byDate[from]; ok {
+			holidays = append(holidays, *h)
+		}
+		from = from.AddDays(1)
+	}
+	return holidays, nil
+}
+
+type getter interface {
+	Get(date model.Date) (model.Holiday, bool, error)
+	GetRange(from, to model.Date) (model.Holidays, error)
+}
+
+// benchHolidays returns weekends of years 1990-2039
+func benchHolidays() model.Holidays {
+	holidays := model.Holidays{}
+	for d := model.NewDate(1990, 1, 1); d.Year < 2040; d = d.AddDays(1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			holidays = append(holidays, model.Holiday{Date: d, Type: model.TypeWeekend})
+		}
+	}
+	return holidays
+}
+
+func benchStores(b *testing.B) map[string]getter {
+	holidays := benchHolidays()
+	s := New()
+	if err := s.Restore(holidays); err != nil {
+		b.Fatalf("Restore failed: %s", err)
+	}
+
+	return map[string]getter{
+		"indexed": s,
+		"naive":   newNaiveStore(holidays),
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for name, s := range benchStores(b) {
+		b.Run(name, func(b *testing.B) {
+			date := model.NewDate(2019, 5, 11)
+			for i := 0; i < b.N; i++ {
+				if _, ok, _ := s.Get(date); !ok {
+					b.Fatalf("holiday not found")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetRange_month(b *testing.B) {
+	benchmarkGetRange(b, model.NewDate(2019, 5, 1), model.NewDate(2019, 5, 31))
+}
+
+func BenchmarkGetRange_year(b *testing.B) {
+	benchmarkGetRange(b, model.NewDate(2019, 1, 1), model.NewDate(2019, 12, 31))
+}
+
+func BenchmarkGetRange_decades(b *testing.B) {
+	benchmarkGetRange(b, model.NewDate(1990, 1, 1), model.NewDate(2039, 12, 31))
+}
+
+func benchmarkGetRange(b *testing.B, from, to model.Date) {
+	for name, s := range benchStores(b) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := s.GetRange(from, to); err != nil {
+					b.Fatalf("GetRange failed: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCountByType_decades(b *testing.B) {
+	s := New()
+	if err := s.Restore(benchHolidays()); err != nil {
+		b.Fatalf("Restore failed: %s", err)
+	}
+
+	// the getter without queries falls back to GetRange
+	getters := map[string]store.HolidayGetter{
+		"indexed":  s,
+		"fallback": struct{ store.HolidayGetter }{s},
+	}
+	from, to := model.NewDate(1990, 1, 1), model.NewDate(2039, 12, 31)
+	for name, g := range getters {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := store.CountByType(g, from, to); err != nil {
+					b.Fatalf("CountByType failed: %s", err)
+				}
+			}
+		})
+	}
+}
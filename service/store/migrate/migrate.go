@@ -0,0 +1,136 @@
+// Package migrate copies calendars between store.Store implementations,
+// e.g. from backups to a persistent store.
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/notify"
+)
+
+// Report is a result of the verification, differences are relative to the
+// source and sorted by date
+type Report struct {
+	// Holidays is the number of source holidays
+	Holidays int
+	// Missing are source holidays absent in the destination
+	Missing model.Holidays
+	// Extra are destination holidays absent in the source
+	Extra model.Holidays
+	// Different are holidays changed in the destination, Old is the source one
+	Different []notify.Change
+	// MissingYears are source loaded years absent in the destination
+	MissingYears []int
+	// ExtraYears are destination loaded years absent in the source
+	ExtraYears []int
+}
+
+// OK reports if the destination matches the source
+func (r Report) OK() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Different) == 0 &&
+		len(r.MissingYears) == 0 && len(r.ExtraYears) == 0
+}
+
+// Err returns error describing differences, nil if the destination matches
+// the source
+func (r Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+
+	diffs := []string{}
+	add := func(n int, what string) {
+		if n > 0 {
+			diffs = append(diffs, fmt.Sprintf("%d %s", n, what))
+		}
+	}
+	add(len(r.Missing), "missing holidays")
+	add(len(r.Extra), "extra holidays")
+	add(len(r.Different), "different holidays")
+	add(len(r.MissingYears), "missing years")
+	add(len(r.ExtraYears), "extra years")
+
+	return fmt.Errorf("destination differs from source: %s", strings.Join(diffs, ", "))
+}
+
+// Copy replaces the destination data with the full dataset of the source,
+// including years loaded without holidays, and verifies the result. Error is
+// returned if the copy fails or the destination doesn't match the source.
+func Copy(dst, src store.Store) (Report, error) {
+	// the destination is not touched, if the source can't be read
+	holidays, err := store.Collect(src)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading source: %s", err)
+	}
+	if err := dst.Restore(holidays); err != nil {
+		return Report{}, fmt.Errorf("error restoring destination: %s", err)
+	}
+
+	// years without holidays are not restored
+	restored := make(map[int]bool)
+	for _, year := range dst.Years() {
+		restored[year] = true
+	}
+	for _, year := range src.Years() {
+		if restored[year] {
+			continue
+		}
+		if err := dst.ReplaceYear(year, nil); err != nil {
+			return Report{}, fmt.Errorf("error loading year %d to destination: %s", year, err)
+		}
+	}
+
+	report, err := Verify(dst, src)
+	if err != nil {
+		return report, err
+	}
+	return report, report.Err()
+}
+
+// Verify compares the destination with the source, error is returned if
+// any of them can't be read
+func Verify(dst, src store.Store) (Report, error) {
+	holidays, err := store.Collect(src)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading source: %s", err)
+	}
+	dstHolidays, err := store.Collect(dst)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading destination: %s", err)
+	}
+	diff := notify.Diff(holidays, dstHolidays)
+
+	different := append(diff.Retyped, diff.Modified...)
+	sort.Slice(different, func(i, j int) bool {
+		return different[i].Old.Date.Before(different[j].Old.Date)
+	})
+
+	return Report{
+		Holidays:     len(holidays),
+		Missing:      diff.Removed,
+		Extra:        diff.Added,
+		Different:    different,
+		MissingYears: subtract(src.Years(), dst.Years()),
+		ExtraYears:   subtract(dst.Years(), src.Years()),
+	}, nil
+}
+
+// subtract returns years of a absent in b, keeping the order
+func subtract(a, b []int) []int {
+	exclude := make(map[int]bool, len(b))
+	for _, year := range b {
+		exclude[year] = true
+	}
+
+	var years []int
+	for _, year := range a {
+		if !exclude[year] {
+			years = append(years, year)
+		}
+	}
+	return years
+}
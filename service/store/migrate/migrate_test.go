@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/notify"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestCopy(t *testing.T) {
+	src, dst := memory.New(), memory.New()
+	if err := src.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := src.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	// the destination data is replaced
+	if err := dst.ReplaceYear(2017, model.Holidays{{Date: model.NewDate(2017, 1, 1), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	report, err := Copy(dst, src)
+	if err != nil {
+		t.Fatalf("Copy failed: %s", err)
+	}
+	if !report.OK() || report.Holidays != len(storetest.Holidays()) {
+		t.Errorf("unexpected report %#v", report)
+	}
+	if dump := dst.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("destination data %#v != expected %#v", dump, storetest.Holidays())
+	}
+	if years := dst.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020, 2021}) {
+		t.Errorf("destination years %v != [2018 2019 2020 2021]", years)
+	}
+}
+
+// brokenReads fails Each, like persistent stores on read errors, Dump of
+// them returns nil then
+type brokenReads struct {
+	store.Store
+}
+
+func (s *brokenReads) Dump() model.Holidays {
+	return nil
+}
+
+func (s *brokenReads) Each(fn func(h model.Holiday) bool) error {
+	return fmt.Errorf("broken")
+}
+
+func TestCopy_sourceFailed(t *testing.T) {
+	src := memory.New()
+	if err := src.Set(model.Holidays{{Date: model.NewDate(2017, 1, 1), Type: model.TypeHoliday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	dst := memory.New()
+	if err := dst.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if _, err := Copy(dst, &brokenReads{Store: src}); err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+	if dump := dst.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("destination data %#v is changed by failed Copy, expected %#v", dump, storetest.Holidays())
+	}
+
+	if _, err := Verify(dst, &brokenReads{Store: src}); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if _, err := Verify(&brokenReads{Store: dst}, src); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	holidays := storetest.Holidays()
+	src, dst := memory.New(), memory.New()
+	if err := src.Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	extra := model.Holiday{Date: model.NewDate(2017, 1, 1), Type: model.TypeHoliday}
+	retyped := model.Holiday{Date: holidays[3].Date, Type: model.TypeHoliday}
+	modified := holidays[2]
+	modified.Name = ""
+	if err := dst.Set(model.Holidays{extra, holidays[0], holidays[1], modified, retyped, holidays[4], holidays[5], holidays[6]}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	expected := Report{
+		Holidays: len(holidays),
+		Missing:  holidays[7:],
+		Extra:    model.Holidays{extra},
+		Different: []notify.Change{
+			{Old: holidays[2], New: modified},
+			{Old: holidays[3], New: retyped},
+		},
+		MissingYears: []int{2020},
+		ExtraYears:   []int{2017},
+	}
+	report, err := Verify(dst, src)
+	if err != nil {
+		t.Fatalf("Verify failed: %s", err)
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("report %#v != expected %#v", report, expected)
+	}
+
+	expectedErr := "destination differs from source: 2 missing holidays, 1 extra holidays, 2 different holidays, 1 missing years, 1 extra years"
+	if err := report.Err(); err == nil || err.Error() != expectedErr {
+		t.Errorf("error %v != expected %q", err, expectedErr)
+	}
+}
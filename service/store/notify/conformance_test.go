@@ -0,0 +1,15 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		return New(memory.New()), func() {}
+	})
+}
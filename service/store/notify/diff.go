@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"sort"
+
+	"github.com/mwf/golidays/model"
+)
+
+// Event describes a change of the store data, lists are sorted by date
+type Event struct {
+	Added   model.Holidays
+	Removed model.Holidays
+	// Retyped are days, which type is changed
+	Retyped []Change
+	// Modified are days with the same type and changed name, reason or
+	// transfer date
+	Modified []Change
+}
+
+// Change is a change of the day
+type Change struct {
+	Old model.Holiday
+	New model.Holiday
+}
+
+// Empty reports if nothing is changed
+func (e Event) Empty() bool {
+	return len(e.Added) == 0 && len(e.Removed) == 0 && len(e.Retyped) == 0 && len(e.Modified) == 0
+}
+
+// Diff returns the change from 'before' to 'after' holidays
+func Diff(before, after model.Holidays) Event {
+	old := make(map[model.Date]model.Holiday, len(before))
+	for _, h := range before {
+		old[h.Date] = h
+	}
+
+	var e Event
+	for _, h := range after {
+		o, ok := old[h.Date]
+		delete(old, h.Date)
+		switch {
+		case !ok:
+			e.Added = append(e.Added, h)
+		case o.Type != h.Type:
+			e.Retyped = append(e.Retyped, Change{Old: o, New: h})
+		case o != h:
+			e.Modified = append(e.Modified, Change{Old: o, New: h})
+		}
+	}
+	for _, h := range old {
+		e.Removed = append(e.Removed, h)
+	}
+
+	sort.Sort(model.HolidaysByDate(e.Added))
+	sort.Sort(model.HolidaysByDate(e.Removed))
+	sortChanges(e.Retyped)
+	sortChanges(e.Modified)
+
+	return e
+}
+
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].New.Date.Before(changes[j].New.Date)
+	})
+}
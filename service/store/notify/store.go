@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Store is a store decorator, which notifies subscribers of data changes.
+// Writes compare the affected data before and after the update, subscribers
+// get an event with the difference, if there is any.
+//
+// Only writes through the decorator are noticed. Writes are serialized, so
+// events come in order of changes.
+type Store struct {
+	store.Store
+
+	// mu serializes writes
+	mu sync.Mutex
+
+	subscribersMu sync.RWMutex
+	subscribers   map[int]func(Event)
+	nextID        int
+}
+
+// check if Store implements Store and HolidayQuerier interfaces
+var (
+	_ store.Store          = &Store{}
+	_ store.HolidayQuerier = &Store{}
+)
+
+func New(s store.Store) *Store {
+	return &Store{
+		Store:       s,
+		subscribers: make(map[int]func(Event)),
+	}
+}
+
+// Subscribe adds the subscriber, which is called synchronously after every
+// effective change. It must not write to the store. The returned function
+// removes the subscriber.
+func (s *Store) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = fn
+
+	return func() {
+		s.subscribersMu.Lock()
+		defer s.subscribersMu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// Set set's holidays to the underlying store
+func (s *Store) Set(holidays model.Holidays) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// only dates between the first and the last holidays of years may change
+	spans := make(map[int][2]model.Date)
+	for _, h := range holidays {
+		span, ok := spans[h.Date.Year]
+		if !ok {
+			span = [2]model.Date{h.Date, h.Date}
+		}
+		if h.Date.Before(span[0]) {
+			span[0] = h.Date
+		}
+		if h.Date.After(span[1]) {
+			span[1] = h.Date
+		}
+		spans[h.Date.Year] = span
+	}
+
+	return s.update(func() (model.Holidays, error) {
+		return s.getSpans(spans)
+	}, func() error {
+		return s.Store.Set(holidays)
+	})
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spans := map[int][2]model.Date{
+		year: {model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31)},
+	}
+
+	return s.update(func() (model.Holidays, error) {
+		return s.getSpans(spans)
+	}, func() error {
+		return s.Store.ReplaceYear(year, holidays)
+	})
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the whole data may change, it's read with Each, so read errors fail
+	// the write instead of publishing the removal of all holidays
+	return s.update(func() (model.Holidays, error) {
+		return store.Collect(s.Store)
+	}, func() error {
+		return s.Store.Restore(holidays)
+	})
+}
+
+// NextHoliday returns the first holiday of the types after the date,
+// queries are passed to the underlying store
+func (s *Store) NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.NextHoliday(s.Store, date, types...)
+}
+
+// PrevHoliday returns the last holiday of the types before the date
+func (s *Store) PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.PrevHoliday(s.Store, date, types...)
+}
+
+// GetRangeByType returns holidays of the types between 'from' and 'to' dates
+func (s *Store) GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	return store.GetRangeByType(s.Store, from, to, types...)
+}
+
+// CountByType returns numbers of holidays between 'from' and 'to' dates by
+// their types
+func (s *Store) CountByType(from, to model.Date) (map[model.HolidayType]int, error) {
+	return store.CountByType(s.Store, from, to)
+}
+
+// update performs the write and publishes the difference of data, returned
+// by get before and after it
+func (s *Store) update(get func() (model.Holidays, error), write func() error) error {
+	before, err := get()
+	if err != nil {
+		return fmt.Errorf("can't get holidays before update: %s", err)
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	after, err := get()
+	if err != nil {
+		return fmt.Errorf("can't get holidays after update: %s", err)
+	}
+
+	if e := Diff(before, after); !e.Empty() {
+		s.publish(e)
+	}
+	return nil
+}
+
+// getSpans returns holidays of the date spans, not loaded years are empty
+func (s *Store) getSpans(spans map[int][2]model.Date) (model.Holidays, error) {
+	holidays := model.Holidays{}
+	for _, span := range spans {
+		spanHolidays, err := s.Store.GetRange(span[0], span[1])
+		if err != nil && !store.IsYearNotLoaded(err) {
+			return nil, err
+		}
+		holidays = append(holidays, spanHolidays...)
+	}
+	return holidays, nil
+}
+
+func (s *Store) publish(e Event) {
+	s.subscribersMu.RLock()
+	defer s.subscribersMu.RUnlock()
+
+	for _, fn := range s.subscribers {
+		fn(e)
+	}
+}
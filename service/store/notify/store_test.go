@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+// newStore returns store with storetest.Holidays and collected events
+func newStore(t *testing.T) (*Store, *[]Event) {
+	s := New(memory.New())
+	if err := s.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	events := []Event{}
+	s.Subscribe(func(e Event) {
+		events = append(events, e)
+	})
+	return s, &events
+}
+
+func TestSet(t *testing.T) {
+	s, events := newStore(t)
+	holidays := storetest.Holidays()
+
+	added := model.Holiday{Date: model.NewDate(2019, 1, 2), Type: model.TypeHoliday}
+	retyped := model.Holiday{Date: holidays[3].Date, Type: model.TypeHoliday}
+	modified := holidays[4]
+	modified.Name = "23 февраля"
+
+	err := s.Set(model.Holidays{added, retyped, modified, holidays[5]})
+	if err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	expected := []Event{{
+		Added:    model.Holidays{added},
+		Retyped:  []Change{{Old: holidays[3], New: retyped}},
+		Modified: []Change{{Old: holidays[4], New: modified}},
+	}}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("events %#v != expected %#v", *events, expected)
+	}
+}
+
+func TestSet_noop(t *testing.T) {
+	s, events := newStore(t)
+
+	if err := s.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := s.Restore(storetest.Holidays()); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if err := s.Set(nil); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if len(*events) != 0 {
+		t.Errorf("events %#v should be empty", *events)
+	}
+}
+
+func TestReplaceYear(t *testing.T) {
+	s, events := newStore(t)
+	holidays := storetest.Holidays()
+
+	added := model.Holiday{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday}
+	if err := s.ReplaceYear(2020, model.Holidays{holidays[7], added}); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	expected := []Event{{
+		Added:   model.Holidays{added},
+		Removed: model.Holidays{holidays[8]},
+	}}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("events %#v != expected %#v", *events, expected)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	s, events := newStore(t)
+	holidays := storetest.Holidays()
+
+	if err := s.Restore(holidays[:2]); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+
+	expected := []Event{{Removed: holidays[2:]}}
+	if !reflect.DeepEqual(*events, expected) {
+		t.Errorf("events %#v != expected %#v", *events, expected)
+	}
+}
+
+// brokenReads fails Each, Dump of persistent stores returns nil then
+type brokenReads struct {
+	store.Store
+}
+
+func (s *brokenReads) Dump() model.Holidays {
+	return nil
+}
+
+func (s *brokenReads) Each(fn func(h model.Holiday) bool) error {
+	return fmt.Errorf("broken")
+}
+
+func TestRestore_readFailed(t *testing.T) {
+	underlying := memory.New()
+	s := New(&brokenReads{Store: underlying})
+	events := []Event{}
+	s.Subscribe(func(e Event) {
+		events = append(events, e)
+	})
+
+	if err := s.Restore(storetest.Holidays()); err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+	if len(underlying.Dump()) != 0 || len(events) != 0 {
+		t.Errorf("Restore is performed despite the read error, events %#v", events)
+	}
+}
+
+func TestSubscribe_unsubscribe(t *testing.T) {
+	s := New(memory.New())
+
+	count := 0
+	unsubscribe := s.Subscribe(func(e Event) {
+		count++
+	})
+	if err := s.Set(storetest.Holidays()[:1]); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	unsubscribe()
+	if err := s.Set(storetest.Holidays()[1:2]); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if count != 1 {
+		t.Errorf("subscriber is called %d times instead of 1", count)
+	}
+}
@@ -0,0 +1,97 @@
+package overlay
+
+import (
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/notify"
+)
+
+// dayChange is a change of a single day of the base or overrides store,
+// ok values are false if there is no holiday
+type dayChange struct {
+	old, new     model.Holiday
+	oldOk, newOk bool
+}
+
+// BaseChanged returns the change of the merged view, made by the event of the
+// base store. Changes of overridden days are hidden by overrides, so they are
+// dropped.
+func (s *Store) BaseChanged(e notify.Event) (notify.Event, error) {
+	before, after := model.Holidays{}, model.Holidays{}
+	for date, c := range changesOf(e) {
+		_, overridden, err := get(s.overrides, date)
+		if err != nil {
+			return notify.Event{}, err
+		}
+		if overridden {
+			continue
+		}
+
+		if c.oldOk {
+			before = append(before, c.old)
+		}
+		if c.newOk {
+			after = append(after, c.new)
+		}
+	}
+
+	return notify.Diff(before, after), nil
+}
+
+// OverridesChanged returns the change of the merged view, made by the event
+// of the overrides store. Overridden days fall back to the base calendar,
+// when their overrides are dropped.
+func (s *Store) OverridesChanged(e notify.Event) (notify.Event, error) {
+	before, after := model.Holidays{}, model.Holidays{}
+	for date, c := range changesOf(e) {
+		base, baseOk, err := get(s.base, date)
+		if err != nil {
+			return notify.Event{}, err
+		}
+
+		before = appendMerged(before, base, baseOk, c.old, c.oldOk)
+		after = appendMerged(after, base, baseOk, c.new, c.newOk)
+	}
+
+	return notify.Diff(before, after), nil
+}
+
+// changesOf returns changes of the event by dates
+func changesOf(e notify.Event) map[model.Date]dayChange {
+	changes := make(map[model.Date]dayChange)
+	for _, h := range e.Added {
+		changes[h.Date] = dayChange{new: h, newOk: true}
+	}
+	for _, h := range e.Removed {
+		changes[h.Date] = dayChange{old: h, oldOk: true}
+	}
+	for _, list := range [][]notify.Change{e.Retyped, e.Modified} {
+		for _, c := range list {
+			changes[c.New.Date] = dayChange{old: c.Old, new: c.New, oldOk: true, newOk: true}
+		}
+	}
+	return changes
+}
+
+// appendMerged appends the day of the merged view, the override wins over the
+// base holiday, removed days are skipped
+func appendMerged(holidays model.Holidays, base model.Holiday, baseOk bool, override model.Holiday, overrideOk bool) model.Holidays {
+	switch {
+	case overrideOk && override.Type == model.TypeWorkday:
+		return holidays
+	case overrideOk:
+		return append(holidays, override)
+	case baseOk:
+		return append(holidays, base)
+	}
+	return holidays
+}
+
+// get returns the holiday of the date, not loaded years have no holidays
+func get(g store.HolidayGetter, date model.Date) (model.Holiday, bool, error) {
+	h, ok, err := g.Get(date)
+	if store.IsYearNotLoaded(err) {
+		return model.Holiday{}, false, nil
+	}
+	return h, ok, err
+}
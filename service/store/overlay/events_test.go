@@ -0,0 +1,63 @@
+package overlay
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/notify"
+)
+
+func TestBaseChanged(t *testing.T) {
+	s := newStore(t)
+
+	// 2019-12-28 is removed and 2019-12-31 is overridden
+	weekend := model.Holiday{Date: model.NewDate(2019, 12, 29), Type: model.TypeWeekend}
+	e := notify.Event{
+		Added:   model.Holidays{{Date: model.NewDate(2019, 12, 27), Type: model.TypeHoliday}},
+		Removed: model.Holidays{{Date: model.NewDate(2019, 12, 28), Type: model.TypeWeekend}, weekend},
+		Retyped: []notify.Change{{
+			Old: model.Holiday{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday},
+			New: model.Holiday{Date: model.NewDate(2019, 12, 31), Type: model.TypeWeekend},
+		}},
+	}
+	expected := notify.Event{
+		Added:   e.Added,
+		Removed: model.Holidays{weekend},
+	}
+
+	merged, err := s.BaseChanged(e)
+	if err != nil {
+		t.Fatalf("BaseChanged failed: %s", err)
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("merged event %#v != expected %#v", merged, expected)
+	}
+}
+
+func TestOverridesChanged(t *testing.T) {
+	s := newStore(t)
+
+	party := model.Holiday{Date: model.NewDate(2019, 12, 30), Type: model.TypeHoliday, Name: "Corporate party recovery"}
+	e := notify.Event{
+		// the removal of 2019-12-28 is reset
+		Removed: model.Holidays{{Date: model.NewDate(2019, 12, 28), Type: model.TypeWorkday}, party},
+		// the removed day is overridden with a holiday
+		Retyped: []notify.Change{{
+			Old: model.Holiday{Date: model.NewDate(2019, 12, 31), Type: model.TypeWorkday},
+			New: model.Holiday{Date: model.NewDate(2019, 12, 31), Type: model.TypeHoliday},
+		}},
+	}
+	expected := notify.Event{
+		Added:   model.Holidays{{Date: model.NewDate(2019, 12, 28), Type: model.TypeWeekend}, {Date: model.NewDate(2019, 12, 31), Type: model.TypeHoliday}},
+		Removed: model.Holidays{party},
+	}
+
+	merged, err := s.OverridesChanged(e)
+	if err != nil {
+		t.Fatalf("OverridesChanged failed: %s", err)
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("merged event %#v != expected %#v", merged, expected)
+	}
+}
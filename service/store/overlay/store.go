@@ -0,0 +1,233 @@
+package overlay
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Store is a store.Store decorator, which layers user-defined overrides on
+// top of the base calendar, e.g. corporate holidays or office closures.
+//
+// Getters and Dump return the merged view. Set and Restore modify the base
+// calendar only, so updates never overwrite the overrides, which are kept in
+// a separate store and modified with Override, Remove and Reset.
+type Store struct {
+	base      store.Store
+	overrides store.Store
+}
+
+// check if Store implements Store interface
+var _ store.Store = &Store{}
+
+// New returns overlay of overrides on top of base store
+func New(base, overrides store.Store) *Store {
+	return &Store{
+		base:      base,
+		overrides: overrides,
+	}
+}
+
+// Base returns the base calendar store
+func (s *Store) Base() store.Store {
+	return s.base
+}
+
+// Overrides returns the store of overrides. Removed days are stored with
+// model.TypeWorkday type.
+func (s *Store) Overrides() store.Store {
+	return s.overrides
+}
+
+// Override adds days or changes their types, overriding the base calendar
+func (s *Store) Override(holidays model.Holidays) error {
+	for _, h := range holidays {
+		if h.Date.IsZero() {
+			return fmt.Errorf("override has no date: %#v", h)
+		}
+	}
+
+	return s.overrides.Set(holidays)
+}
+
+// Remove makes the days ordinary working days, whatever the base calendar says
+func (s *Store) Remove(dates ...model.Date) error {
+	holidays := make(model.Holidays, 0, len(dates))
+	for _, date := range dates {
+		holidays = append(holidays, model.Holiday{Date: date, Type: model.TypeWorkday})
+	}
+
+	return s.Override(holidays)
+}
+
+// Reset drops overrides of the days, so the base calendar is used for them
+func (s *Store) Reset(dates ...model.Date) error {
+	reset := make(map[model.Date]bool, len(dates))
+	for _, date := range dates {
+		reset[date] = true
+	}
+
+	// overrides are read with Each, so read errors don't drop all of them
+	overrides, err := store.Collect(s.overrides)
+	if err != nil {
+		return fmt.Errorf("can't get overrides: %s", err)
+	}
+	kept := make(model.Holidays, 0, len(overrides))
+	for _, h := range overrides {
+		if !reset[h.Date] {
+			kept = append(kept, h)
+		}
+	}
+
+	return s.overrides.Restore(kept)
+}
+
+// Set sets holidays to the base calendar
+func (s *Store) Set(holidays model.Holidays) error {
+	return s.base.Set(holidays)
+}
+
+// ReplaceYear replaces the year in the base calendar, overrides are kept
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	return s.base.ReplaceYear(year, holidays)
+}
+
+// Get finds holiday by date in overrides, then in the base calendar.
+// If nothing found or the day is removed - returned bool value is false
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	// the base calendar defines loaded years
+	baseH, baseOk, err := s.base.Get(date)
+	if err != nil {
+		return model.Holiday{}, false, err
+	}
+
+	h, ok, err := s.overrides.Get(date)
+	if err != nil && !store.IsYearNotLoaded(err) {
+		return model.Holiday{}, false, err
+	}
+	if ok {
+		if h.Type == model.TypeWorkday {
+			return model.Holiday{}, false, nil
+		}
+		return h, true, nil
+	}
+
+	return baseH, baseOk, nil
+}
+
+// GetRange returns merged holidays between 'from' and 'to' dates
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	base, err := s.base.GetRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// overrides are usually sparse, so get them by years to skip gaps
+	overrides := model.Holidays{}
+	for _, year := range s.overrides.Years() {
+		yearFrom, yearTo := model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31)
+		if yearTo.Before(from) || yearFrom.After(to) {
+			continue
+		}
+		if yearFrom.Before(from) {
+			yearFrom = from
+		}
+		if yearTo.After(to) {
+			yearTo = to
+		}
+
+		yearOverrides, err := s.overrides.GetRange(yearFrom, yearTo)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, yearOverrides...)
+	}
+
+	return merge(base, overrides), nil
+}
+
+// Years returns loaded years of the base calendar
+func (s *Store) Years() []int {
+	return s.base.Years()
+}
+
+// Dump returns merged holidays
+func (s *Store) Dump() model.Holidays {
+	return merge(s.base.Dump(), s.overrides.Dump())
+}
+
+// Each calls fn for merged holidays in date order, until fn returns false.
+// Like Dump, it includes overrides of years, which are not loaded to the base
+// calendar.
+func (s *Store) Each(fn func(h model.Holiday) bool) error {
+	for _, year := range union(s.base.Years(), s.overrides.Years()) {
+		from, to := model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31)
+
+		base, err := s.base.GetRange(from, to)
+		if err != nil && !store.IsYearNotLoaded(err) {
+			return err
+		}
+		overrides, err := s.overrides.GetRange(from, to)
+		if err != nil && !store.IsYearNotLoaded(err) {
+			return err
+		}
+
+		for _, h := range merge(base, overrides) {
+			if !fn(h) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Restore purges the base calendar and sets provided, overrides are kept
+func (s *Store) Restore(holidays model.Holidays) error {
+	return s.base.Restore(holidays)
+}
+
+// union returns sorted years of both lists
+func union(a, b []int) []int {
+	set := make(map[int]bool, len(a)+len(b))
+	years := make([]int, 0, len(a)+len(b))
+	for _, list := range [][]int{a, b} {
+		for _, year := range list {
+			if !set[year] {
+				set[year] = true
+				years = append(years, year)
+			}
+		}
+	}
+	sort.Ints(years)
+	return years
+}
+
+// merge applies overrides to base holidays, the result is sorted by date
+func merge(base, overrides model.Holidays) model.Holidays {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	byDate := make(map[model.Date]model.Holiday, len(base)+len(overrides))
+	for _, h := range base {
+		byDate[h.Date] = h
+	}
+	for _, h := range overrides {
+		if h.Type == model.TypeWorkday {
+			delete(byDate, h.Date)
+			continue
+		}
+		byDate[h.Date] = h
+	}
+
+	holidays := make(model.Holidays, 0, len(byDate))
+	for _, h := range byDate {
+		holidays = append(holidays, h)
+	}
+	sort.Sort(model.HolidaysByDate(holidays))
+
+	return holidays
+}
@@ -0,0 +1,126 @@
+package overlay
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/memory"
+)
+
+func newStore(t *testing.T) *Store {
+	base := memory.New()
+	err := base.Set(model.Holidays{
+		{Date: model.NewDate(2019, 12, 28), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 12, 29), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday},
+		{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday},
+	})
+	if err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	s := New(base, memory.New())
+	err = s.Override(model.Holidays{
+		{Date: model.NewDate(2019, 12, 30), Type: model.TypeHoliday, Name: "Corporate party recovery"},
+		{Date: model.NewDate(2019, 12, 31), Type: model.TypeHoliday},
+	})
+	if err != nil {
+		t.Fatalf("Override failed: %s", err)
+	}
+	if err := s.Remove(model.NewDate(2019, 12, 28)); err != nil {
+		t.Fatalf("Remove failed: %s", err)
+	}
+
+	return s
+}
+
+func TestGet(t *testing.T) {
+	s := newStore(t)
+
+	cases := []struct {
+		date model.Date
+		typ  model.HolidayType
+		ok   bool
+	}{
+		{model.NewDate(2019, 12, 28), "", false},
+		{model.NewDate(2019, 12, 29), model.TypeWeekend, true},
+		{model.NewDate(2019, 12, 30), model.TypeHoliday, true},
+		{model.NewDate(2019, 12, 31), model.TypeHoliday, true},
+		{model.NewDate(2020, 1, 2), "", false},
+	}
+	for _, tc := range cases {
+		h, ok, err := s.Get(tc.date)
+		if err != nil {
+			t.Fatalf("Get failed: %s", err)
+		}
+		if ok != tc.ok || h.Type != tc.typ {
+			t.Errorf("Get(%s) = %q, %t; expected %q, %t", tc.date, h.Type, ok, tc.typ, tc.ok)
+		}
+	}
+}
+
+func TestGetRange(t *testing.T) {
+	s := newStore(t)
+
+	holidays, err := s.GetRange(model.NewDate(2019, 12, 28), model.NewDate(2019, 12, 31))
+	if err != nil {
+		t.Fatalf("GetRange failed: %s", err)
+	}
+
+	expected := model.Holidays{
+		{Date: model.NewDate(2019, 12, 29), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 12, 30), Type: model.TypeHoliday, Name: "Corporate party recovery"},
+		{Date: model.NewDate(2019, 12, 31), Type: model.TypeHoliday},
+	}
+	if !reflect.DeepEqual(holidays, expected) {
+		t.Errorf("merged holidays %#v != expected %#v", holidays, expected)
+	}
+
+	if dump := s.Dump(); len(dump) != 4 {
+		t.Errorf("merged dump %#v should contain 4 holidays", dump)
+	}
+}
+
+func TestSetRestore_keepOverrides(t *testing.T) {
+	s := newStore(t)
+
+	err := s.Restore(model.Holidays{
+		{Date: model.NewDate(2019, 12, 28), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday},
+	})
+	if err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if err := s.Set(model.Holidays{{Date: model.NewDate(2019, 12, 30), Type: model.TypePreholiday}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if overrides := s.Overrides().Dump(); len(overrides) != 3 {
+		t.Errorf("overrides %#v should be kept", overrides)
+	}
+	if h, _, _ := s.Get(model.NewDate(2019, 12, 30)); h.Type != model.TypeHoliday {
+		t.Errorf("override is not applied after Set: %#v", h)
+	}
+	if _, ok, _ := s.Get(model.NewDate(2019, 12, 28)); ok {
+		t.Errorf("removed day is found after Restore")
+	}
+}
+
+func TestReset(t *testing.T) {
+	s := newStore(t)
+
+	if err := s.Reset(model.NewDate(2019, 12, 28), model.NewDate(2019, 12, 31)); err != nil {
+		t.Fatalf("Reset failed: %s", err)
+	}
+
+	if h, _, _ := s.Get(model.NewDate(2019, 12, 31)); h.Type != model.TypePreholiday {
+		t.Errorf("base holiday is not restored after Reset: %#v", h)
+	}
+	if h, _, _ := s.Get(model.NewDate(2019, 12, 28)); h.Type != model.TypeWeekend {
+		t.Errorf("base holiday is not restored after Reset: %#v", h)
+	}
+	if h, _, _ := s.Get(model.NewDate(2019, 12, 30)); h.Type != model.TypeHoliday {
+		t.Errorf("not reset override is lost: %#v", h)
+	}
+}
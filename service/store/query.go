@@ -0,0 +1,124 @@
+package store
+
+import (
+	"time"
+
+	"github.com/mwf/golidays/model"
+)
+
+// HolidayQuerier is an optional interface of getters, which answer queries
+// without fetching and filtering ranges. Use NextHoliday, PrevHoliday,
+// GetRangeByType and CountByType functions, they fall back to GetRange for
+// other getters.
+//
+// Types filter holidays, all types match if none provided. Searches fail with
+// *YearNotLoadedError on entering a year, which is not loaded.
+type HolidayQuerier interface {
+	// NextHoliday returns the first holiday of the types after the date
+	NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error)
+	// PrevHoliday returns the last holiday of the types before the date
+	PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error)
+	// GetRangeByType returns holidays of the types between 'from' and 'to'
+	// dates
+	GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error)
+	// CountByType returns numbers of holidays between 'from' and 'to' dates
+	// by their types
+	CountByType(from, to model.Date) (map[model.HolidayType]int, error)
+}
+
+// NextHoliday returns the first holiday of the types after the date
+func NextHoliday(g HolidayGetter, date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	if q, ok := g.(HolidayQuerier); ok {
+		return q.NextHoliday(date, types...)
+	}
+
+	match := MatchTypes(types...)
+	for from := date.AddDays(1); ; from = model.NewDate(from.Year+1, time.January, 1) {
+		holidays, err := g.GetRange(from, model.NewDate(from.Year, time.December, 31))
+		if err != nil {
+			return model.Holiday{}, err
+		}
+		for _, h := range holidays {
+			if match(h.Type) {
+				return h, nil
+			}
+		}
+	}
+}
+
+// PrevHoliday returns the last holiday of the types before the date
+func PrevHoliday(g HolidayGetter, date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	if q, ok := g.(HolidayQuerier); ok {
+		return q.PrevHoliday(date, types...)
+	}
+
+	match := MatchTypes(types...)
+	for to := date.AddDays(-1); ; to = model.NewDate(to.Year-1, time.December, 31) {
+		holidays, err := g.GetRange(model.NewDate(to.Year, time.January, 1), to)
+		if err != nil {
+			return model.Holiday{}, err
+		}
+		for i := len(holidays) - 1; i >= 0; i-- {
+			if match(holidays[i].Type) {
+				return holidays[i], nil
+			}
+		}
+	}
+}
+
+// GetRangeByType returns holidays of the types between 'from' and 'to' dates
+func GetRangeByType(g HolidayGetter, from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	if q, ok := g.(HolidayQuerier); ok {
+		return q.GetRangeByType(from, to, types...)
+	}
+
+	holidays, err := g.GetRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	match := MatchTypes(types...)
+	filtered := model.Holidays{}
+	for _, h := range holidays {
+		if match(h.Type) {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered, nil
+}
+
+// CountByType returns numbers of holidays between 'from' and 'to' dates by
+// their types
+func CountByType(g HolidayGetter, from, to model.Date) (map[model.HolidayType]int, error) {
+	if q, ok := g.(HolidayQuerier); ok {
+		return q.CountByType(from, to)
+	}
+
+	holidays, err := g.GetRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[model.HolidayType]int)
+	for _, h := range holidays {
+		counts[h.Type]++
+	}
+	return counts, nil
+}
+
+// MatchTypes returns a filter of the types, it matches any type if no types
+// provided
+func MatchTypes(types ...model.HolidayType) func(model.HolidayType) bool {
+	switch len(types) {
+	case 0:
+		return func(model.HolidayType) bool { return true }
+	case 1:
+		return func(t model.HolidayType) bool { return t == types[0] }
+	}
+
+	set := make(map[model.HolidayType]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return func(t model.HolidayType) bool { return set[t] }
+}
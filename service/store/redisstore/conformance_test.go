@@ -0,0 +1,15 @@
+package redisstore
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		server, cleanup := runRedis(t)
+		return newStore(server, "golidays:RU"), cleanup
+	})
+}
@@ -0,0 +1,234 @@
+package redisstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Store is a Redis storage, which may be shared by several replicas.
+//
+// Holidays are kept in "<prefix>:holidays" sorted set, scored by dates as
+// 20190509 numbers, so range queries are ZRANGEBYSCORE. Loaded years are kept
+// in "<prefix>:years" sorted set. Every write and every range read is a single
+// MULTI/EXEC transaction.
+type Store struct {
+	client      redis.Cmdable
+	holidaysKey string
+	yearsKey    string
+}
+
+// check if Store implements Store interface
+var _ store.Store = &Store{}
+
+// New returns store, keys are prefixed by the prefix, e.g. "golidays:RU"
+func New(client redis.Cmdable, prefix string) *Store {
+	return &Store{
+		client:      client,
+		holidaysKey: prefix + ":holidays",
+		yearsKey:    prefix + ":years",
+	}
+}
+
+// Set set's holidays to the storage
+func (s *Store) Set(holidays model.Holidays) error {
+	return s.update(func(pipe redis.Pipeliner) error {
+		return s.put(pipe, holidays)
+	})
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	if err := store.CheckYear(year, holidays); err != nil {
+		return err
+	}
+
+	return s.update(func(pipe redis.Pipeliner) error {
+		from, to := model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31)
+		pipe.ZRemRangeByScore(s.holidaysKey, score(from), score(to))
+		pipe.ZAdd(s.yearsKey, yearMember(year))
+		return s.put(pipe, holidays)
+	})
+}
+
+// Get finds holiday by date and returns it.
+// If nothing found - returned bool value is false
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	holidays, err := s.GetRange(date, date)
+	if err != nil {
+		return model.Holiday{}, false, err
+	}
+	if len(holidays) == 0 {
+		return model.Holiday{}, false, nil
+	}
+
+	return holidays[0], true, nil
+}
+
+// GetRange returns holidays between 'from' and 'to' dates.
+// Returns empty slice if no holidays found
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	var yearsCmd, holidaysCmd *redis.StringSliceCmd
+	_, err := s.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		yearsCmd = pipe.ZRangeByScore(s.yearsKey, redis.ZRangeBy{
+			Min: strconv.Itoa(from.Year),
+			Max: strconv.Itoa(to.Year),
+		})
+		holidaysCmd = pipe.ZRangeByScore(s.holidaysKey, redis.ZRangeBy{
+			Min: score(from),
+			Max: score(to),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	years := make(map[int]bool)
+	for _, member := range yearsCmd.Val() {
+		year, err := strconv.Atoi(member)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse year %q: %s", member, err)
+		}
+		years[year] = true
+	}
+	loaded := func(year int) bool {
+		return years[year]
+	}
+	if err := store.CheckCoverage(loaded, from, to); err != nil {
+		return nil, err
+	}
+
+	return decode(holidaysCmd.Val())
+}
+
+// Dump returns all holidays from store sorted by date. It returns nil, if the
+// data can't be read.
+func (s *Store) Dump() model.Holidays {
+	members, err := s.client.ZRange(s.holidaysKey, 0, -1).Result()
+	if err != nil {
+		return nil
+	}
+
+	holidays, err := decode(members)
+	if err != nil {
+		return nil
+	}
+	return holidays
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	return s.update(func(pipe redis.Pipeliner) error {
+		pipe.Del(s.holidaysKey, s.yearsKey)
+		return s.put(pipe, holidays)
+	})
+}
+
+// Each calls fn for all holidays in date order, until fn returns false.
+// Holidays are fetched by years.
+func (s *Store) Each(fn func(h model.Holiday) bool) error {
+	years, err := s.years()
+	if err != nil {
+		return err
+	}
+
+	return store.EachYear(s, years, fn)
+}
+
+// Years returns loaded years in ascending order. It returns nil, if the
+// data can't be read.
+func (s *Store) Years() []int {
+	years, err := s.years()
+	if err != nil {
+		return nil
+	}
+	return years
+}
+
+// years returns loaded years in ascending order
+func (s *Store) years() ([]int, error) {
+	members, err := s.client.ZRange(s.yearsKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	years := make([]int, 0, len(members))
+	for _, member := range members {
+		year, err := strconv.Atoi(member)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse year %q: %s", member, err)
+		}
+		years = append(years, year)
+	}
+	return years, nil
+}
+
+// update runs commands of fn in MULTI/EXEC transaction
+func (s *Store) update(fn func(pipe redis.Pipeliner) error) error {
+	_, err := s.client.TxPipelined(fn)
+	return err
+}
+
+// put queues commands to set holidays and their years, a holiday replaces
+// the one of the same date
+func (s *Store) put(pipe redis.Pipeliner, holidays model.Holidays) error {
+	members := make(map[string]redis.Z, len(holidays))
+	years := make(map[int]bool)
+	for _, h := range holidays {
+		value, err := json.Marshal(h)
+		if err != nil {
+			return fmt.Errorf("can't encode holiday %s: %s", h.Date, err)
+		}
+
+		// the last holiday of the date wins
+		dateScore := score(h.Date)
+		members[dateScore] = redis.Z{Score: float64(scoreOf(h.Date)), Member: string(value)}
+		years[h.Date.Year] = true
+	}
+
+	for dateScore, member := range members {
+		pipe.ZRemRangeByScore(s.holidaysKey, dateScore, dateScore)
+		pipe.ZAdd(s.holidaysKey, member)
+	}
+	for year := range years {
+		pipe.ZAdd(s.yearsKey, yearMember(year))
+	}
+
+	return nil
+}
+
+func decode(members []string) (model.Holidays, error) {
+	holidays := make(model.Holidays, 0, len(members))
+	for _, member := range members {
+		var h model.Holiday
+		if err := json.Unmarshal([]byte(member), &h); err != nil {
+			return nil, fmt.Errorf("can't decode holiday: %s", err)
+		}
+		holidays = append(holidays, h)
+	}
+	return holidays, nil
+}
+
+// scoreOf returns the date as 20190509 number, which keeps the order of dates
+func scoreOf(date model.Date) int {
+	return date.Year*10000 + int(date.Month)*100 + date.Day
+}
+
+// score returns the date score as a string for range commands
+func score(date model.Date) string {
+	return strconv.Itoa(scoreOf(date))
+}
+
+func yearMember(year int) redis.Z {
+	return redis.Z{Score: float64(year), Member: strconv.Itoa(year)}
+}
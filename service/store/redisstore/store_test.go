@@ -0,0 +1,69 @@
+package redisstore
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+// runRedis runs in-process Redis, which is stopped by returned cleanup
+// function
+func runRedis(t *testing.T) (*miniredis.Miniredis, func()) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("miniredis.Run failed: %s", err)
+	}
+	return server, server.Close
+}
+
+func newStore(server *miniredis.Miniredis, prefix string) *Store {
+	return New(redis.NewClient(&redis.Options{Addr: server.Addr()}), prefix)
+}
+
+func TestReplicas(t *testing.T) {
+	server, cleanup := runRedis(t)
+	defer cleanup()
+
+	holidays := storetest.Holidays()
+	if err := newStore(server, "golidays:RU").Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	other := model.Holidays{{Date: model.NewDate(2019, 8, 30), Type: model.TypeHoliday}}
+	if err := newStore(server, "golidays:RU-TA").Set(other); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	// another replica with its own client sees the same data
+	if dump := newStore(server, "golidays:RU").Dump(); !reflect.DeepEqual(dump, holidays) {
+		t.Errorf("stored data %#v != original %#v", dump, holidays)
+	}
+	if dump := newStore(server, "golidays:RU-TA").Dump(); !reflect.DeepEqual(dump, other) {
+		t.Errorf("stored data %#v != original %#v", dump, other)
+	}
+}
+
+func TestSet_retype(t *testing.T) {
+	server, cleanup := runRedis(t)
+	defer cleanup()
+	store := newStore(server, "golidays:RU")
+
+	date := model.NewDate(2019, 5, 9)
+	if err := store.Set(model.Holidays{{Date: date, Type: model.TypeHoliday, Name: "День Победы"}}); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	retyped := model.Holidays{
+		{Date: date, Type: model.TypeWeekend},
+		{Date: date, Type: model.TypeWorkday},
+	}
+	if err := store.Set(retyped); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if dump := store.Dump(); !reflect.DeepEqual(dump, retyped[1:]) {
+		t.Errorf("stored data %#v != expected %#v", dump, retyped[1:])
+	}
+}
@@ -0,0 +1,16 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		db := openDB(t)
+		return newStore(t, db, model.CalendarRU), func() { db.Close() }
+	})
+}
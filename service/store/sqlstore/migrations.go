@@ -0,0 +1,82 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations are schema changes, a migration version is its index + 1.
+// Applied migrations must never be changed, add new ones instead.
+var migrations = []string{
+	// 1: years and holidays of calendars
+	`CREATE TABLE holiday_years (
+		calendar VARCHAR(16) NOT NULL,
+		year     INTEGER NOT NULL,
+		PRIMARY KEY (calendar, year)
+	);
+	CREATE TABLE holidays (
+		calendar         VARCHAR(16) NOT NULL,
+		date             DATE NOT NULL,
+		type             VARCHAR(32) NOT NULL,
+		name             TEXT NOT NULL DEFAULT '',
+		reason           TEXT NOT NULL DEFAULT '',
+		transferred_from DATE,
+		PRIMARY KEY (calendar, date)
+	);
+	CREATE INDEX holidays_date_idx ON holidays (date);`,
+}
+
+// SchemaVersion returns the version of applied migrations, 0 if none
+func SchemaVersion(db *sql.DB) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(createSchemaTable); err != nil {
+		return 0, fmt.Errorf("can't create schema table: %s", err)
+	}
+	return schemaVersion(tx)
+}
+
+// Migrate applies new migrations in a single transaction
+func Migrate(db *sql.DB, dialect Dialect) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(createSchemaTable); err != nil {
+		return fmt.Errorf("can't create schema table: %s", err)
+	}
+	version, err := schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than known %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			return fmt.Errorf("migration %d failed: %s", i+1, err)
+		}
+		if _, err := tx.Exec(dialect.rebind("INSERT INTO holidays_schema (version) VALUES (?)"), i+1); err != nil {
+			return fmt.Errorf("can't save schema version %d: %s", i+1, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+const createSchemaTable = `CREATE TABLE IF NOT EXISTS holidays_schema (version INTEGER NOT NULL)`
+
+func schemaVersion(tx *sql.Tx) (int, error) {
+	var version sql.NullInt64
+	if err := tx.QueryRow("SELECT MAX(version) FROM holidays_schema").Scan(&version); err != nil {
+		return 0, fmt.Errorf("can't get schema version: %s", err)
+	}
+	return int(version.Int64), nil
+}
@@ -0,0 +1,333 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Dialect is an SQL dialect of the database
+type Dialect int
+
+const (
+	// SQLite uses "?" placeholders
+	SQLite Dialect = iota
+	// Postgres uses "$1" placeholders
+	Postgres
+)
+
+// rebind replaces "?" placeholders of the query with dialect ones
+func (d Dialect) rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+const (
+	holidayColumns = "date, type, name, reason, transferred_from"
+
+	upsertHoliday = `INSERT INTO holidays (calendar, ` + holidayColumns + `) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (calendar, date) DO UPDATE SET
+		type = excluded.type, name = excluded.name, reason = excluded.reason, transferred_from = excluded.transferred_from`
+	insertYear = `INSERT INTO holiday_years (calendar, year) VALUES (?, ?) ON CONFLICT (calendar, year) DO NOTHING`
+)
+
+// Store is a database/sql storage, holidays of calendars are kept in
+// "holidays" table, loaded years are kept in "holiday_years" one. Every write
+// is a single transaction.
+//
+// The schema is created and upgraded by Migrate, see migrations.
+type Store struct {
+	db       *sql.DB
+	dialect  Dialect
+	calendar string
+}
+
+// check if Store implements Store interface
+var _ store.Store = &Store{}
+
+// New returns store of the calendar, migrating the schema if needed
+func New(db *sql.DB, dialect Dialect, calendar model.Calendar) (*Store, error) {
+	if !calendar.Valid() {
+		return nil, fmt.Errorf("calendar %q is invalid", calendar)
+	}
+	if err := Migrate(db, dialect); err != nil {
+		return nil, fmt.Errorf("can't migrate schema: %s", err)
+	}
+
+	return &Store{
+		db:       db,
+		dialect:  dialect,
+		calendar: calendar.String(),
+	}, nil
+}
+
+// Set set's holidays to the storage
+func (s *Store) Set(holidays model.Holidays) error {
+	return s.update(func(tx *sql.Tx) error {
+		return s.insert(tx, holidays)
+	})
+}
+
+// ReplaceYear purges all holidays of the year and sets provided
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	if err := store.CheckYear(year, holidays); err != nil {
+		return err
+	}
+
+	return s.update(func(tx *sql.Tx) error {
+		from, to := yearBounds(year)
+		_, err := tx.Exec(s.dialect.rebind("DELETE FROM holidays WHERE calendar = ? AND date BETWEEN ? AND ?"), s.calendar, from, to)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(s.dialect.rebind(insertYear), s.calendar, year); err != nil {
+			return err
+		}
+		return s.insert(tx, holidays)
+	})
+}
+
+// Get finds holiday by date and returns it.
+// If nothing found - returned bool value is false
+func (s *Store) Get(date model.Date) (model.Holiday, bool, error) {
+	row := s.db.QueryRow(s.dialect.rebind("SELECT "+holidayColumns+" FROM holidays WHERE calendar = ? AND date = ?"), s.calendar, date)
+	h, err := scanHoliday(row)
+	switch {
+	case err == nil:
+		return h, true, nil
+	case err != sql.ErrNoRows:
+		return model.Holiday{}, false, err
+	}
+
+	// holidays exist in loaded years only
+	years, err := s.loadedYears(s.db, date.Year, date.Year)
+	if err != nil {
+		return model.Holiday{}, false, err
+	}
+	if !years[date.Year] {
+		return model.Holiday{}, false, &store.YearNotLoadedError{Year: date.Year}
+	}
+
+	return model.Holiday{}, false, nil
+}
+
+// GetRange returns holidays between 'from' and 'to' dates.
+// Returns empty slice if no holidays found
+func (s *Store) GetRange(from, to model.Date) (model.Holidays, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	years, err := s.loadedYears(tx, from.Year, to.Year)
+	if err != nil {
+		return nil, err
+	}
+	loaded := func(year int) bool {
+		return years[year]
+	}
+	if err := store.CheckCoverage(loaded, from, to); err != nil {
+		return nil, err
+	}
+
+	return s.query(tx, "WHERE calendar = ? AND date BETWEEN ? AND ? ORDER BY date", s.calendar, from, to)
+}
+
+// Dump returns all holidays from store sorted by date. It returns nil, if the
+// database can't be read.
+func (s *Store) Dump() model.Holidays {
+	holidays, err := s.query(s.db, "WHERE calendar = ? ORDER BY date", s.calendar)
+	if err != nil {
+		return nil
+	}
+	return holidays
+}
+
+// Restore purges all items and sets provided
+func (s *Store) Restore(holidays model.Holidays) error {
+	return s.update(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(s.dialect.rebind("DELETE FROM holidays WHERE calendar = ?"), s.calendar); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(s.dialect.rebind("DELETE FROM holiday_years WHERE calendar = ?"), s.calendar); err != nil {
+			return err
+		}
+		return s.insert(tx, holidays)
+	})
+}
+
+// Each calls fn for all holidays in date order, until fn returns false.
+// Holidays are queried by years, fn is called out of queries, so it may use
+// the store.
+func (s *Store) Each(fn func(h model.Holiday) bool) error {
+	years, err := s.years()
+	if err != nil {
+		return err
+	}
+
+	return store.EachYear(s, years, fn)
+}
+
+// Years returns loaded years in ascending order. It returns nil, if the
+// database can't be read.
+func (s *Store) Years() []int {
+	years, err := s.years()
+	if err != nil {
+		return nil
+	}
+	return years
+}
+
+// years returns loaded years in ascending order
+func (s *Store) years() ([]int, error) {
+	rows, err := s.db.Query(s.dialect.rebind("SELECT year FROM holiday_years WHERE calendar = ? ORDER BY year"), s.calendar)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	years := []int{}
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, err
+		}
+		years = append(years, year)
+	}
+
+	return years, rows.Err()
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// update runs fn in a transaction
+func (s *Store) update(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insert upserts holidays and their years
+func (s *Store) insert(tx *sql.Tx, holidays model.Holidays) error {
+	if len(holidays) == 0 {
+		return nil
+	}
+
+	upsert, err := tx.Prepare(s.dialect.rebind(upsertHoliday))
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+
+	years := make(map[int]bool)
+	for _, h := range holidays {
+		if !years[h.Date.Year] {
+			if _, err := tx.Exec(s.dialect.rebind(insertYear), s.calendar, h.Date.Year); err != nil {
+				return err
+			}
+			years[h.Date.Year] = true
+		}
+
+		if _, err := upsert.Exec(s.calendar, h.Date, string(h.Type), h.Name, h.Reason, h.TransferredFrom); err != nil {
+			return fmt.Errorf("can't set holiday %s: %s", h.Date, err)
+		}
+	}
+
+	return nil
+}
+
+// query returns holidays, selected by the query condition
+func (s *Store) query(q queryer, condition string, args ...interface{}) (model.Holidays, error) {
+	rows, err := q.Query(s.dialect.rebind("SELECT "+holidayColumns+" FROM holidays "+condition), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holidays := model.Holidays{}
+	for rows.Next() {
+		h, err := scanHoliday(rows)
+		if err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, h)
+	}
+
+	return holidays, rows.Err()
+}
+
+// loadedYears returns loaded years between 'from' and 'to'
+func (s *Store) loadedYears(q queryer, from, to int) (map[int]bool, error) {
+	rows, err := q.Query(s.dialect.rebind("SELECT year FROM holiday_years WHERE calendar = ? AND year BETWEEN ? AND ?"), s.calendar, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	years := make(map[int]bool)
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, err
+		}
+		years[year] = true
+	}
+
+	return years, rows.Err()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanHoliday(row scanner) (model.Holiday, error) {
+	var (
+		h   model.Holiday
+		typ string
+	)
+	if err := row.Scan(&h.Date, &typ, &h.Name, &h.Reason, &h.TransferredFrom); err != nil {
+		return model.Holiday{}, err
+	}
+	h.Type = model.HolidayType(typ)
+
+	return h, nil
+}
+
+// yearBounds returns the first and the last dates of the year
+func yearBounds(year int) (model.Date, model.Date) {
+	return model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31)
+}
@@ -0,0 +1,114 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+// openDB opens an in-memory SQLite database, a single connection keeps it
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+func newStore(t *testing.T, db *sql.DB, calendar model.Calendar) *Store {
+	store, err := New(db, SQLite, calendar)
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	return store
+}
+
+func TestMigrate(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	if version, err := SchemaVersion(db); err != nil || version != 0 {
+		t.Fatalf("unexpected version of empty database %d, error %v", version, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := Migrate(db, SQLite); err != nil {
+			t.Fatalf("Migrate failed: %s", err)
+		}
+		version, err := SchemaVersion(db)
+		if err != nil {
+			t.Fatalf("SchemaVersion failed: %s", err)
+		}
+		if version != len(migrations) {
+			t.Errorf("schema version %d != %d", version, len(migrations))
+		}
+	}
+}
+
+// TestRestore_otherCalendars checks that calendars sharing the table don't
+// affect each other
+func TestRestore_otherCalendars(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+	store := newStore(t, db, model.CalendarRU)
+	other := newStore(t, db, model.CalendarRUTatarstan)
+
+	if err := store.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := other.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	restored := model.Holidays{{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday}}
+	if err := store.Restore(restored); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if dump := store.Dump(); !reflect.DeepEqual(dump, restored) {
+		t.Errorf("stored data %#v != restored %#v", dump, restored)
+	}
+	if dump := other.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("stored data %#v of other calendar != original %#v", dump, storetest.Holidays())
+	}
+	if years := other.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020}) {
+		t.Errorf("years %v of other calendar != [2018 2019 2020]", years)
+	}
+}
+
+func TestRestore_rollback(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+	store := newStore(t, db, model.CalendarRU)
+
+	if err := store.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	// NOT NULL constraint fails on the second holiday
+	broken := model.Holidays{
+		{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday},
+		{Type: model.TypeHoliday},
+	}
+	if err := store.Restore(broken); err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+	if dump := store.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("stored data %#v != original %#v after failed Restore", dump, storetest.Holidays())
+	}
+}
+
+func TestDialect_rebind(t *testing.T) {
+	query := "SELECT * FROM holidays WHERE calendar = ? AND date BETWEEN ? AND ?"
+	expected := "SELECT * FROM holidays WHERE calendar = $1 AND date BETWEEN $2 AND $3"
+	if rebound := Postgres.rebind(query); rebound != expected {
+		t.Errorf("rebound query %q != %q", rebound, expected)
+	}
+	if rebound := SQLite.rebind(query); rebound != query {
+		t.Errorf("rebound query %q != %q", rebound, query)
+	}
+}
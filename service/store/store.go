@@ -1,6 +1,7 @@
 package store
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/mwf/golidays/model"
@@ -13,18 +14,103 @@ type Store interface {
 	HolidayDumpRestorer
 }
 
+// HolidayGetter gets holidays of loaded years. Queries of dates outside the
+// loaded years fail with *YearNotLoadedError, because an absent holiday
+// means a working day only for the known calendar.
 type HolidayGetter interface {
-	Get(date time.Time) (model.Holiday, bool, error)
-	GetRange(from, to time.Time) (model.Holidays, error)
+	Get(date model.Date) (model.Holiday, bool, error)
+	GetRange(from, to model.Date) (model.Holidays, error)
+	// Years returns loaded years in ascending order
+	Years() []int
 }
 
 type HolidaySetter interface {
 	Set(holidays model.Holidays) error
+	// ReplaceYear atomically purges all items of the year and sets provided,
+	// which must belong to the year
+	ReplaceYear(year int, holidays model.Holidays) error
 }
 
 type HolidayDumpRestorer interface {
 	// Dump returns all items in store
 	Dump() model.Holidays
+	// Each calls fn for all items in date order, until fn returns false
+	Each(fn func(h model.Holiday) bool) error
 	// Restore purges all items and sets provided
 	Restore(holidays model.Holidays) error
 }
+
+// CheckYear returns error if any of holidays does not belong to the year,
+// it's a helper for ReplaceYear implementations
+func CheckYear(year int, holidays model.Holidays) error {
+	for _, h := range holidays {
+		if h.Date.Year != year {
+			return fmt.Errorf("holiday %s does not belong to year %d", h.Date, year)
+		}
+	}
+	return nil
+}
+
+// YearNotLoadedError is returned by getters for dates of years, which are not
+// loaded to the store
+type YearNotLoadedError struct {
+	Year int
+}
+
+func (e *YearNotLoadedError) Error() string {
+	return fmt.Sprintf("year %d is not loaded", e.Year)
+}
+
+// IsYearNotLoaded reports if err is *YearNotLoadedError
+func IsYearNotLoaded(err error) bool {
+	_, ok := err.(*YearNotLoadedError)
+	return ok
+}
+
+// CheckCoverage returns *YearNotLoadedError for the first year between 'from'
+// and 'to' dates, which is absent in loaded years
+func CheckCoverage(loaded func(year int) bool, from, to model.Date) error {
+	for year := from.Year; year <= to.Year; year++ {
+		if !loaded(year) {
+			return &YearNotLoadedError{Year: year}
+		}
+	}
+	return nil
+}
+
+// Collect returns all items of the store read with Each. Unlike Dump, it
+// returns the read error, so a failed read is not taken for an empty store.
+func Collect(d HolidayDumpRestorer) (model.Holidays, error) {
+	holidays := model.Holidays{}
+	err := d.Each(func(h model.Holiday) bool {
+		holidays = append(holidays, h)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+// EachYear is an Each helper, which calls fn for holidays of the years in
+// date order, until fn returns false. Holidays are fetched from the getter by
+// years, so only a year is kept in memory. Years, which are not loaded
+// anymore, are skipped.
+func EachYear(g HolidayGetter, years []int, fn func(h model.Holiday) bool) error {
+	for _, year := range years {
+		holidays, err := g.GetRange(model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31))
+		if IsYearNotLoaded(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, h := range holidays {
+			if !fn(h) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,611 @@
+// Package storetest is a conformance suite for store.Store implementations.
+// Run it from a test of the implementation:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+//			return memory.New(), func() {}
+//		})
+//	}
+package storetest
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Factory returns a new empty store and a cleanup function, which is called
+// when the case is finished
+type Factory func(t *testing.T) (s store.Store, cleanup func())
+
+// Case is a conformance case
+type Case struct {
+	Name string
+	Test func(t *testing.T, s store.Store)
+}
+
+// Cases are all conformance cases, Run runs them
+var Cases = []Case{
+	{"Set", testSet},
+	{"Set_upsert", testSetUpsert},
+	{"Set_callerMemory", testSetCallerMemory},
+	{"Get_yearNotLoaded", testGetYearNotLoaded},
+	{"Get_timeOfDay", testGetTimeOfDay},
+	{"GetRange_inclusive", testGetRangeInclusive},
+	{"GetRange_empty", testGetRangeEmpty},
+	{"GetRange_inverted", testGetRangeInverted},
+	{"GetRange_years", testGetRangeYears},
+	{"GetRange_yearNotLoaded", testGetRangeYearNotLoaded},
+	{"ReplaceYear", testReplaceYear},
+	{"ReplaceYear_wrongYear", testReplaceYearWrongYear},
+	{"Restore", testRestore},
+	{"Each", testEach},
+	{"Each_stop", testEachStop},
+	{"Years", testYears},
+	{"NextHoliday", testNextHoliday},
+	{"PrevHoliday", testPrevHoliday},
+	{"GetRangeByType", testGetRangeByType},
+	{"CountByType", testCountByType},
+	{"Concurrent_replaceYear", testConcurrentReplaceYear},
+	{"Concurrent_set", testConcurrentSet},
+}
+
+// Run runs all conformance cases against new stores
+func Run(t *testing.T, newStore Factory) {
+	for _, c := range Cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			s, cleanup := newStore(t)
+			defer cleanup()
+
+			c.Test(t, s)
+		})
+	}
+}
+
+// Holidays returns holidays of 2018-2020 with all the optional fields set
+// for some of them
+func Holidays() model.Holidays {
+	return model.Holidays{
+		{Date: model.NewDate(2018, 12, 29), Type: model.TypeWorkingWeekend},
+		{Date: model.NewDate(2018, 12, 31), Type: model.TypeTransferred, TransferredFrom: model.NewDate(2018, 12, 29)},
+		{Date: model.NewDate(2019, 1, 1), Type: model.TypeHoliday, Name: "Новогодние каникулы"},
+		{Date: model.NewDate(2019, 2, 22), Type: model.TypePreholiday},
+		{Date: model.NewDate(2019, 2, 23), Type: model.TypeHoliday, Name: "День защитника Отечества"},
+		{Date: model.NewDate(2019, 2, 24), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 10), Type: model.TypeTransferred, Reason: "перенос выходного дня", TransferredFrom: model.NewDate(2019, 1, 5)},
+		{Date: model.NewDate(2020, 2, 29), Type: model.TypeWeekend},
+		{Date: model.NewDate(2020, 12, 31), Type: model.TypeWorkday},
+	}
+}
+
+func set(t *testing.T, s store.Store, holidays model.Holidays) {
+	if err := s.Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+}
+
+func getRange(t *testing.T, s store.Store, from, to model.Date) model.Holidays {
+	holidays, err := s.GetRange(from, to)
+	if err != nil {
+		t.Fatalf("GetRange %s - %s failed: %s", from, to, err)
+	}
+	return holidays
+}
+
+func testSet(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+
+	for _, h := range holidays {
+		storedH, ok, err := s.Get(h.Date)
+		if err != nil {
+			t.Fatalf("Get failed: %s", err)
+		}
+		if !ok {
+			t.Errorf("holiday %#v not found", h)
+		}
+		if storedH != h {
+			t.Errorf("stored data %#v != original %#v", storedH, h)
+		}
+	}
+
+	_, ok, err := s.Get(model.NewDate(2019, 1, 2))
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if ok {
+		t.Errorf("Nonexisting date found")
+	}
+
+	if dump := s.Dump(); !reflect.DeepEqual(dump, holidays) {
+		t.Errorf("dump %#v != original %#v", dump, holidays)
+	}
+}
+
+func testSetUpsert(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+	set(t, s, model.Holidays{
+		{Date: model.NewDate(2019, 1, 1), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 1, 2), Type: model.TypeHoliday},
+		{Date: model.NewDate(2019, 1, 1), Type: model.TypeHoliday},
+	})
+
+	expected := model.Holidays{
+		{Date: model.NewDate(2019, 1, 1), Type: model.TypeHoliday},
+		{Date: model.NewDate(2019, 1, 2), Type: model.TypeHoliday},
+		Holidays()[3],
+	}
+	storedH := getRange(t, s, model.NewDate(2019, 1, 1), model.NewDate(2019, 2, 22))
+	if !reflect.DeepEqual(storedH, expected) {
+		t.Errorf("stored data %#v != expected %#v, the last holiday of a date wins", storedH, expected)
+	}
+}
+
+func testSetCallerMemory(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+	for i := range holidays {
+		holidays[i].Type = model.TypeWorkday
+		holidays[i].Name = "changed"
+	}
+
+	storedH := getRange(t, s, model.NewDate(2018, 1, 1), model.NewDate(2020, 12, 31))
+	for i := range storedH {
+		storedH[i].Type = model.TypeWorkday
+	}
+
+	if dump := s.Dump(); !reflect.DeepEqual(dump, Holidays()) {
+		t.Errorf("stored data %#v is changed by the caller, expected %#v", dump, Holidays())
+	}
+}
+
+func testGetYearNotLoaded(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	_, ok, err := s.Get(model.NewDate(2021, 1, 1))
+	if e, isErr := err.(*store.YearNotLoadedError); !isErr || e.Year != 2021 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok {
+		t.Errorf("Nonexisting date found")
+	}
+}
+
+// testGetTimeOfDay checks that the date of any time of the day is found,
+// including times in locations with offsets
+func testGetTimeOfDay(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	msk := time.FixedZone("MSK", 3*60*60)
+	times := []time.Time{
+		time.Date(2019, 2, 23, 0, 0, 0, 0, msk),
+		time.Date(2019, 2, 23, 23, 59, 59, 0, msk),
+		time.Date(2019, 2, 22, 21, 30, 0, 0, time.UTC),
+	}
+	for _, tm := range times {
+		h, ok, err := s.Get(model.DateIn(tm, msk))
+		if err != nil {
+			t.Fatalf("Get failed: %s", err)
+		}
+		if !ok || h.Date != model.NewDate(2019, 2, 23) {
+			t.Errorf("holiday of %s not found: %#v", tm, h)
+		}
+	}
+
+	// dates are normalized
+	h, ok, err := s.Get(model.NewDate(2019, 1, 32))
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if ok {
+		t.Errorf("holiday %#v of 2019-01-32 (2019-02-01) found", h)
+	}
+}
+
+func testGetRangeInclusive(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+
+	storedH := getRange(t, s, model.NewDate(2019, 2, 22), model.NewDate(2019, 2, 24))
+	if !reflect.DeepEqual(storedH, holidays[3:6]) {
+		t.Errorf("range with bounds on holidays %#v != expected %#v", storedH, holidays[3:6])
+	}
+
+	storedH = getRange(t, s, model.NewDate(2019, 2, 23), model.NewDate(2019, 2, 23))
+	if !reflect.DeepEqual(storedH, holidays[4:5]) {
+		t.Errorf("single day range %#v != expected %#v", storedH, holidays[4:5])
+	}
+}
+
+func testGetRangeEmpty(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	storedH := getRange(t, s, model.NewDate(2019, 1, 2), model.NewDate(2019, 2, 21))
+	if storedH == nil || len(storedH) != 0 {
+		t.Errorf("holidays should be an empty slice: %#v", storedH)
+	}
+}
+
+func testGetRangeInverted(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	_, err := s.GetRange(model.NewDate(2019, 2, 24), model.NewDate(2019, 2, 22))
+	if err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+}
+
+func testGetRangeYears(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	// unsorted input
+	set(t, s, append(holidays[5:], holidays[:5]...))
+
+	storedH := getRange(t, s, model.NewDate(2018, 12, 31), model.NewDate(2020, 2, 29))
+	if !reflect.DeepEqual(storedH, holidays[1:8]) {
+		t.Errorf("stored data %#v != expected %#v", storedH, holidays[1:8])
+	}
+}
+
+func testGetRangeYearNotLoaded(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	_, err := s.GetRange(model.NewDate(2020, 12, 1), model.NewDate(2021, 1, 31))
+	if e, ok := err.(*store.YearNotLoadedError); !ok || e.Year != 2021 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = s.GetRange(model.NewDate(2017, 12, 1), model.NewDate(2018, 1, 31))
+	if e, ok := err.(*store.YearNotLoadedError); !ok || e.Year != 2017 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func testReplaceYear(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+
+	replaced := model.Holidays{
+		{Date: model.NewDate(2019, 1, 7), Type: model.TypeHoliday},
+		{Date: model.NewDate(2019, 1, 2), Type: model.TypeHoliday},
+	}
+	if err := s.ReplaceYear(2019, replaced); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	expected := model.Holidays{holidays[0], holidays[1], replaced[1], replaced[0], holidays[7], holidays[8]}
+	if dump := s.Dump(); !reflect.DeepEqual(dump, expected) {
+		t.Errorf("stored data %#v != expected %#v", dump, expected)
+	}
+
+	// the replaced empty year is loaded
+	storedH := getRange(t, s, model.NewDate(2021, 1, 1), model.NewDate(2021, 12, 31))
+	if len(storedH) != 0 {
+		t.Errorf("holidays should be empty: %#v", storedH)
+	}
+}
+
+func testReplaceYearWrongYear(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	err := s.ReplaceYear(2019, model.Holidays{{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday}})
+	if err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+	if dump := s.Dump(); !reflect.DeepEqual(dump, Holidays()) {
+		t.Errorf("stored data %#v is changed by failed ReplaceYear", dump)
+	}
+}
+
+func testRestore(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	restored := model.Holidays{
+		{Date: model.NewDate(2022, 1, 1), Type: model.TypeHoliday},
+		{Date: model.NewDate(2021, 12, 31), Type: model.TypePreholiday},
+	}
+	if err := s.Restore(restored); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+
+	expected := model.Holidays{restored[1], restored[0]}
+	if dump := s.Dump(); !reflect.DeepEqual(dump, expected) {
+		t.Errorf("stored data %#v != restored %#v", dump, expected)
+	}
+	if years := s.Years(); !reflect.DeepEqual(years, []int{2021, 2022}) {
+		t.Errorf("years %v != [2021 2022] after Restore", years)
+	}
+	if _, _, err := s.Get(model.NewDate(2019, 1, 1)); !store.IsYearNotLoaded(err) {
+		t.Errorf("purged year is loaded, error: %v", err)
+	}
+
+	if err := s.Restore(nil); err != nil {
+		t.Fatalf("Restore failed: %s", err)
+	}
+	if dump := s.Dump(); len(dump) != 0 {
+		t.Errorf("dump %#v should be empty", dump)
+	}
+	if years := s.Years(); len(years) != 0 {
+		t.Errorf("years %v should be empty", years)
+	}
+}
+
+func testYears(t *testing.T, s store.Store) {
+	if years := s.Years(); len(years) != 0 {
+		t.Fatalf("years of empty store %v should be empty", years)
+	}
+
+	set(t, s, model.Holidays{
+		{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday},
+		{Date: model.NewDate(2009, 12, 31), Type: model.TypePreholiday},
+	})
+	if err := s.ReplaceYear(2010, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+	if years := s.Years(); !reflect.DeepEqual(years, []int{2009, 2010, 2020}) {
+		t.Errorf("years %v != [2009 2010 2020]", years)
+	}
+}
+
+// monthOf returns all days of January 2019 of the type
+func monthOf(typ model.HolidayType) model.Holidays {
+	holidays := make(model.Holidays, 0, 31)
+	for day := 1; day <= 31; day++ {
+		holidays = append(holidays, model.Holiday{Date: model.NewDate(2019, 1, day), Type: typ})
+	}
+	return holidays
+}
+
+// testConcurrentReplaceYear checks that readers always see a consistent year:
+// every update replaces the whole year with holidays of the same type
+func testConcurrentReplaceYear(t *testing.T, s store.Store) {
+	types := []model.HolidayType{model.TypeWeekend, model.TypeHoliday, model.TypePreholiday}
+	if err := s.ReplaceYear(2019, monthOf(types[0])); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				holidays, err := s.GetRange(model.NewDate(2019, 1, 1), model.NewDate(2019, 12, 31))
+				if err != nil {
+					t.Errorf("GetRange failed: %s", err)
+					return
+				}
+				if len(holidays) != 31 {
+					t.Errorf("got %d holidays instead of 31", len(holidays))
+					return
+				}
+				for _, h := range holidays {
+					if h.Type != holidays[0].Type {
+						t.Errorf("inconsistent year: %s != %s", h.Type, holidays[0].Type)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := s.ReplaceYear(2019, monthOf(types[i%len(types)])); err != nil {
+			t.Errorf("ReplaceYear failed: %s", err)
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+// testConcurrentSet checks that concurrent writers of different years don't
+// lose updates
+func testConcurrentSet(t *testing.T, s store.Store) {
+	const writers = 8
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(year int) {
+			defer wg.Done()
+			for day := 1; day <= 10; day++ {
+				h := model.Holiday{Date: model.NewDate(year, 1, day), Type: model.TypeHoliday}
+				if err := s.Set(model.Holidays{h}); err != nil {
+					t.Errorf("Set failed: %s", err)
+					return
+				}
+				if _, ok, err := s.Get(h.Date); err != nil || !ok {
+					t.Errorf("holiday %s is not found after Set, error: %v", h.Date, err)
+					return
+				}
+			}
+		}(2000 + i)
+	}
+	wg.Wait()
+
+	if dump := s.Dump(); len(dump) != writers*10 {
+		t.Errorf("got %d holidays instead of %d", len(dump), writers*10)
+	}
+	if years := s.Years(); len(years) != writers {
+		t.Errorf("years %v, expected %d of them", years, writers)
+	}
+}
+
+// query is a case of NextHoliday or PrevHoliday
+type query struct {
+	date     model.Date
+	types    []model.HolidayType
+	expected model.Holiday
+	// notLoaded is the year, which fails the search
+	notLoaded int
+}
+
+func checkQueries(t *testing.T, name string, queries []query, fn func(date model.Date, types ...model.HolidayType) (model.Holiday, error)) {
+	for _, q := range queries {
+		h, err := fn(q.date, q.types...)
+		if q.notLoaded != 0 {
+			if e, isErr := err.(*store.YearNotLoadedError); !isErr || e.Year != q.notLoaded {
+				t.Errorf("%s %s %v: unexpected error: %v", name, q.date, q.types, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s failed: %s", name, err)
+		}
+		if h != q.expected {
+			t.Errorf("%s %s %v: %#v != expected %#v", name, q.date, q.types, h, q.expected)
+		}
+	}
+}
+
+func testNextHoliday(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+
+	checkQueries(t, "NextHoliday", []query{
+		{date: holidays[0].Date, expected: holidays[1]},
+		{date: holidays[1].Date, types: []model.HolidayType{model.TypeHoliday}, expected: holidays[2]},
+		{date: holidays[2].Date, types: []model.HolidayType{model.TypeWeekend, model.TypeWorkday}, expected: holidays[5]},
+		{date: holidays[6].Date, types: []model.HolidayType{model.TypeWorkday}, expected: holidays[8]},
+		{date: holidays[8].Date, notLoaded: 2021},
+		{date: holidays[5].Date, types: []model.HolidayType{model.TypeHoliday}, notLoaded: 2021},
+	}, func(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+		return store.NextHoliday(s, date, types...)
+	})
+}
+
+func testPrevHoliday(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+
+	checkQueries(t, "PrevHoliday", []query{
+		{date: holidays[2].Date, expected: holidays[1]},
+		{date: holidays[4].Date, types: []model.HolidayType{model.TypeHoliday}, expected: holidays[2]},
+		{date: holidays[7].Date, types: []model.HolidayType{model.TypeTransferred}, expected: holidays[6]},
+		{date: model.NewDate(2021, 1, 1), expected: holidays[8]},
+		{date: holidays[0].Date, notLoaded: 2017},
+	}, func(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+		return store.PrevHoliday(s, date, types...)
+	})
+}
+
+func testGetRangeByType(t *testing.T, s store.Store) {
+	holidays := Holidays()
+	set(t, s, holidays)
+	from, to := model.NewDate(2018, 12, 1), model.NewDate(2019, 12, 31)
+
+	storedH, err := store.GetRangeByType(s, from, to, model.TypeHoliday, model.TypeTransferred)
+	if err != nil {
+		t.Fatalf("GetRangeByType failed: %s", err)
+	}
+	expected := model.Holidays{holidays[1], holidays[2], holidays[4], holidays[6]}
+	if !reflect.DeepEqual(storedH, expected) {
+		t.Errorf("holidays %#v != expected %#v", storedH, expected)
+	}
+
+	storedH, err = store.GetRangeByType(s, from, to)
+	if err != nil {
+		t.Fatalf("GetRangeByType failed: %s", err)
+	}
+	if !reflect.DeepEqual(storedH, holidays[:7]) {
+		t.Errorf("holidays of all types %#v != expected %#v", storedH, holidays[:7])
+	}
+
+	if _, err := store.GetRangeByType(s, to, from, model.TypeHoliday); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	_, err = store.GetRangeByType(s, from, model.NewDate(2021, 1, 1), model.TypeHoliday)
+	if e, isErr := err.(*store.YearNotLoadedError); !isErr || e.Year != 2021 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testCountByType(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	counts, err := store.CountByType(s, model.NewDate(2019, 1, 1), model.NewDate(2020, 6, 30))
+	if err != nil {
+		t.Fatalf("CountByType failed: %s", err)
+	}
+	expected := map[model.HolidayType]int{
+		model.TypeHoliday:     2,
+		model.TypePreholiday:  1,
+		model.TypeWeekend:     2,
+		model.TypeTransferred: 1,
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("counts %v != expected %v", counts, expected)
+	}
+
+	_, err = store.CountByType(s, model.NewDate(2017, 1, 1), model.NewDate(2019, 1, 1))
+	if e, isErr := err.(*store.YearNotLoadedError); !isErr || e.Year != 2017 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testEach(t *testing.T, s store.Store) {
+	// unsorted holidays are iterated in date order
+	holidays := Holidays()
+	set(t, s, append(holidays[4:], holidays[:4]...))
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	iterated := model.Holidays{}
+	err := s.Each(func(h model.Holiday) bool {
+		iterated = append(iterated, h)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Each failed: %s", err)
+	}
+	if !reflect.DeepEqual(iterated, Holidays()) {
+		t.Errorf("iterated %#v != expected %#v", iterated, Holidays())
+	}
+}
+
+func testEachStop(t *testing.T, s store.Store) {
+	set(t, s, Holidays())
+
+	iterated := model.Holidays{}
+	err := s.Each(func(h model.Holiday) bool {
+		iterated = append(iterated, h)
+		return len(iterated) < 3
+	})
+	if err != nil {
+		t.Fatalf("Each failed: %s", err)
+	}
+	if !reflect.DeepEqual(iterated, Holidays()[:3]) {
+		t.Errorf("iterated %#v != expected %#v", iterated, Holidays()[:3])
+	}
+
+	// the store may be used by fn
+	count := 0
+	err = s.Each(func(h model.Holiday) bool {
+		if _, _, err := s.Get(h.Date); err != nil {
+			t.Errorf("Get failed: %s", err)
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Each failed: %s", err)
+	}
+	if count != len(Holidays()) {
+		t.Errorf("%d holidays iterated instead of %d", count, len(Holidays()))
+	}
+}
@@ -0,0 +1,20 @@
+package tee
+
+import (
+	"testing"
+
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	for _, policy := range []Policy{FailFast, BestEffort} {
+		policy := policy
+		t.Run(policy.String(), func(t *testing.T) {
+			storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+				return New(policy, nil, memory.New(), memory.New()), func() {}
+			})
+		})
+	}
+}
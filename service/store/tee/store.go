@@ -0,0 +1,209 @@
+package tee
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/logger"
+	"github.com/mwf/golidays/service/store"
+)
+
+// Policy defines how writes handle failures of secondaries
+type Policy int
+
+const (
+	// FailFast returns the first error of secondaries, the rest of them are
+	// not written
+	FailFast Policy = iota
+	// BestEffort writes all secondaries, logging errors
+	BestEffort
+)
+
+func (p Policy) String() string {
+	switch p {
+	case FailFast:
+		return "fail-fast"
+	case BestEffort:
+		return "best-effort"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+}
+
+// Store is a composite store, which serves reads from the primary store and
+// writes to the primary, then to secondaries, e.g. memory.Store for fast
+// reads and a durable store for restarts.
+//
+// A write fails if the primary fails, secondaries are not written then.
+// Secondaries, which missed a write, are lagging until they are rebuilt
+// with Resync.
+type Store struct {
+	// store.Store is the primary store
+	store.Store
+	secondaries []store.Store
+	policy      Policy
+	logger      logger.Logger
+
+	// mu serializes writes, so secondaries get them in the same order
+	mu      sync.Mutex
+	lagging []bool
+}
+
+// check if Store implements Store and HolidayQuerier interfaces
+var (
+	_ store.Store          = &Store{}
+	_ store.HolidayQuerier = &Store{}
+)
+
+// New returns the composite of primary and secondaries stores
+func New(policy Policy, log logger.Logger, primary store.Store, secondaries ...store.Store) *Store {
+	if log == nil {
+		log = &logger.NilLogger{}
+	}
+
+	return &Store{
+		Store:       primary,
+		secondaries: secondaries,
+		policy:      policy,
+		logger:      log,
+		lagging:     make([]bool, len(secondaries)),
+	}
+}
+
+// Primary returns the primary store
+func (s *Store) Primary() store.Store {
+	return s.Store
+}
+
+// Secondaries returns secondaries in order they are written
+func (s *Store) Secondaries() []store.Store {
+	return s.secondaries
+}
+
+// Set set's holidays to all stores
+func (s *Store) Set(holidays model.Holidays) error {
+	return s.write("Set", func(st store.Store) error {
+		return st.Set(holidays)
+	})
+}
+
+// ReplaceYear replaces the year in all stores
+func (s *Store) ReplaceYear(year int, holidays model.Holidays) error {
+	return s.write("ReplaceYear", func(st store.Store) error {
+		return st.ReplaceYear(year, holidays)
+	})
+}
+
+// Restore purges all items and sets provided in all stores
+func (s *Store) Restore(holidays model.Holidays) error {
+	return s.write("Restore", func(st store.Store) error {
+		return st.Restore(holidays)
+	})
+}
+
+// NextHoliday returns the first holiday of the types after the date,
+// queries are served by the primary store
+func (s *Store) NextHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.NextHoliday(s.Store, date, types...)
+}
+
+// PrevHoliday returns the last holiday of the types before the date
+func (s *Store) PrevHoliday(date model.Date, types ...model.HolidayType) (model.Holiday, error) {
+	return store.PrevHoliday(s.Store, date, types...)
+}
+
+// GetRangeByType returns holidays of the types between 'from' and 'to' dates
+func (s *Store) GetRangeByType(from, to model.Date, types ...model.HolidayType) (model.Holidays, error) {
+	return store.GetRangeByType(s.Store, from, to, types...)
+}
+
+// CountByType returns numbers of holidays between 'from' and 'to' dates by
+// their types
+func (s *Store) CountByType(from, to model.Date) (map[model.HolidayType]int, error) {
+	return store.CountByType(s.Store, from, to)
+}
+
+// Lagging returns indexes of secondaries, which missed writes
+func (s *Store) Lagging() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lagging := []int{}
+	for i, ok := range s.lagging {
+		if ok {
+			lagging = append(lagging, i)
+		}
+	}
+	return lagging
+}
+
+// Resync rebuilds the secondary with the index from the primary's data,
+// years loaded without holidays are loaded to the secondary as well
+func (s *Store) Resync(i int) error {
+	if i < 0 || i >= len(s.secondaries) {
+		return fmt.Errorf("unknown secondary %d", i)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the secondary is not touched, if the primary can't be read
+	holidays, err := store.Collect(s.Store)
+	if err != nil {
+		return fmt.Errorf("secondary %d resync failed: can't read primary: %s", i, err)
+	}
+
+	secondary := s.secondaries[i]
+	if err := secondary.Restore(holidays); err != nil {
+		return fmt.Errorf("secondary %d resync failed: %s", i, err)
+	}
+
+	loaded := make(map[int]bool)
+	for _, year := range secondary.Years() {
+		loaded[year] = true
+	}
+	for _, year := range s.Store.Years() {
+		if loaded[year] {
+			continue
+		}
+		if err := secondary.ReplaceYear(year, nil); err != nil {
+			return fmt.Errorf("secondary %d resync failed: %s", i, err)
+		}
+	}
+
+	s.lagging[i] = false
+	s.logger.Infof("secondary %d is resynced", i)
+	return nil
+}
+
+// write performs the write to the primary, then to secondaries according to
+// the policy
+func (s *Store) write(op string, fn func(st store.Store) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := fn(s.Store); err != nil {
+		return err
+	}
+
+	for i, secondary := range s.secondaries {
+		err := fn(secondary)
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("secondary %d %s failed: %s", i, op, err)
+		if s.policy == FailFast {
+			for j := i; j < len(s.secondaries); j++ {
+				s.lagging[j] = true
+			}
+			return err
+		}
+
+		s.lagging[i] = true
+		s.logger.Warningf("%s, it's lagging now", err)
+	}
+
+	return nil
+}
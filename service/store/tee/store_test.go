@@ -0,0 +1,176 @@
+package tee
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/logger"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+	"github.com/mwf/golidays/service/store/storetest"
+)
+
+// failing fails writes and Each while broken
+type failing struct {
+	store.Store
+	broken bool
+}
+
+func (f *failing) Set(holidays model.Holidays) error {
+	if f.broken {
+		return fmt.Errorf("broken")
+	}
+	return f.Store.Set(holidays)
+}
+
+func (f *failing) ReplaceYear(year int, holidays model.Holidays) error {
+	if f.broken {
+		return fmt.Errorf("broken")
+	}
+	return f.Store.ReplaceYear(year, holidays)
+}
+
+func (f *failing) Restore(holidays model.Holidays) error {
+	if f.broken {
+		return fmt.Errorf("broken")
+	}
+	return f.Store.Restore(holidays)
+}
+
+func (f *failing) Each(fn func(h model.Holiday) bool) error {
+	if f.broken {
+		return fmt.Errorf("broken")
+	}
+	return f.Store.Each(fn)
+}
+
+// newStore returns tee of memory primary and two failing secondaries
+func newStore(policy Policy) (*Store, []*failing) {
+	secondaries := []*failing{{Store: memory.New()}, {Store: memory.New()}}
+	return New(policy, &logger.NilLogger{}, memory.New(), secondaries[0], secondaries[1]), secondaries
+}
+
+func TestSet(t *testing.T) {
+	s, secondaries := newStore(FailFast)
+	holidays := storetest.Holidays()
+
+	if err := s.Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	for i, secondary := range secondaries {
+		if dump := secondary.Dump(); !reflect.DeepEqual(dump, holidays) {
+			t.Errorf("secondary %d data %#v != expected %#v", i, dump, holidays)
+		}
+		if years := secondary.Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020, 2021}) {
+			t.Errorf("secondary %d years %v != [2018 2019 2020 2021]", i, years)
+		}
+	}
+	if lagging := s.Lagging(); len(lagging) != 0 {
+		t.Errorf("lagging %v should be empty", lagging)
+	}
+}
+
+func TestSet_failFast(t *testing.T) {
+	s, secondaries := newStore(FailFast)
+	secondaries[0].broken = true
+
+	if err := s.Set(storetest.Holidays()); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	// the primary is written anyway
+	if dump := s.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("primary data %#v != expected %#v", dump, storetest.Holidays())
+	}
+	if dump := secondaries[1].Dump(); len(dump) != 0 {
+		t.Errorf("secondary after the failed one is written: %#v", dump)
+	}
+	if lagging := s.Lagging(); !reflect.DeepEqual(lagging, []int{0, 1}) {
+		t.Errorf("lagging %v != [0 1]", lagging)
+	}
+}
+
+func TestSet_bestEffort(t *testing.T) {
+	s, secondaries := newStore(BestEffort)
+	secondaries[0].broken = true
+
+	if err := s.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if dump := secondaries[1].Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("secondary data %#v != expected %#v", dump, storetest.Holidays())
+	}
+	if lagging := s.Lagging(); !reflect.DeepEqual(lagging, []int{0}) {
+		t.Errorf("lagging %v != [0]", lagging)
+	}
+}
+
+func TestSet_primaryFailed(t *testing.T) {
+	primary := &failing{Store: memory.New(), broken: true}
+	secondary := memory.New()
+	s := New(BestEffort, nil, primary, secondary)
+
+	if err := s.Set(storetest.Holidays()); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if dump := secondary.Dump(); len(dump) != 0 {
+		t.Errorf("secondary is written after the primary failure: %#v", dump)
+	}
+}
+
+func TestResync(t *testing.T) {
+	s, secondaries := newStore(BestEffort)
+	secondaries[0].broken = true
+
+	if err := s.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+	if err := s.ReplaceYear(2021, nil); err != nil {
+		t.Fatalf("ReplaceYear failed: %s", err)
+	}
+
+	if err := s.Resync(0); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	secondaries[0].broken = false
+	if err := s.Resync(0); err != nil {
+		t.Fatalf("Resync failed: %s", err)
+	}
+
+	if dump := secondaries[0].Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("resynced data %#v != expected %#v", dump, storetest.Holidays())
+	}
+	if years := secondaries[0].Years(); !reflect.DeepEqual(years, []int{2018, 2019, 2020, 2021}) {
+		t.Errorf("resynced years %v != [2018 2019 2020 2021]", years)
+	}
+	if lagging := s.Lagging(); len(lagging) != 0 {
+		t.Errorf("lagging %v should be empty", lagging)
+	}
+
+	if err := s.Resync(2); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+}
+
+func TestResync_primaryFailed(t *testing.T) {
+	primary := &failing{Store: memory.New()}
+	secondary := memory.New()
+	s := New(BestEffort, nil, primary, secondary)
+
+	if err := s.Set(storetest.Holidays()); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	primary.broken = true
+	if err := s.Resync(0); err == nil {
+		t.Errorf("Error should not be empty")
+	}
+	if dump := secondary.Dump(); !reflect.DeepEqual(dump, storetest.Holidays()) {
+		t.Errorf("secondary %#v is changed by failed Resync, expected %#v", dump, storetest.Holidays())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mwf/golidays/crawler"
+	"github.com/mwf/golidays/model"
 	"github.com/mwf/golidays/service/logger"
 	"github.com/mwf/golidays/service/store"
 )
@@ -15,34 +16,39 @@ const (
 	minUpdatePeriod = time.Minute
 )
 
-// Updater performs periodic holiday updates in storage for current year
+// Updater performs periodic holiday updates in storage for current year and
+// the next one since november
 type Updater struct {
-	storage store.Store
-	crawler crawler.Crawler
-	period  time.Duration
-	logger  logger.Logger
+	calendar model.Calendar
+	storage  store.Store
+	crawler  crawler.Crawler
+	period   time.Duration
+	logger   logger.Logger
+	now      func() time.Time
 
 	runOnce sync.Once
 	done    chan struct{}
 }
 
-// New returns new updater instance
-func New(storage store.Store, crawler crawler.Crawler, period time.Duration, log logger.Logger) (*Updater, error) {
+// New returns new updater instance for the calendar
+func New(calendar model.Calendar, storage store.Store, crawler crawler.Crawler, period time.Duration, log logger.Logger) (*Updater, error) {
 	if period < minUpdatePeriod {
 		return nil, fmt.Errorf("period is too low: %s < %s", period, minUpdatePeriod)
 	}
 
 	return &Updater{
-		storage: storage,
-		crawler: crawler,
-		period:  period,
-		logger:  log,
-		done:    make(chan struct{}),
+		calendar: calendar,
+		storage:  storage,
+		crawler:  crawler,
+		period:   period,
+		logger:   log,
+		now:      time.Now,
+		done:     make(chan struct{}),
 	}, nil
 }
 
 func (u *Updater) String() string {
-	return fmt.Sprintf("Updater {period: %s}", u.period)
+	return fmt.Sprintf("Updater {calendar: %s, period: %s}", u.calendar, u.period)
 }
 
 // Run runs asynchronous update loop. Multiple calls do nothing - the loop started
@@ -77,26 +83,34 @@ func (u *Updater) loop() {
 }
 
 func (u *Updater) perform() {
-	startedAt := time.Now()
+	startedAt := u.now()
 
 	u.logger.Debugf("perform %s", u)
 	defer func() {
 		u.logger.Infof("perform finished in %s", time.Now().Sub(startedAt))
 	}()
 
-	year := startedAt.Year()
-	if startedAt.Month() >= time.November {
-		// start scraping next year in november
-		year += 1
-	}
-	h, err := u.crawler.ScrapeYear(year)
-	if err != nil {
-		u.logger.Errorf("crawler.ScrapeYear error: %s", err)
-		return
+	for _, year := range yearsToUpdate(startedAt) {
+		h, err := u.crawler.ScrapeYear(year)
+		if err != nil {
+			u.logger.Errorf("crawler.ScrapeYear(%d) error: %s", year, err)
+			continue
+		}
+
+		// replace the whole year to drop days, removed by a new decree
+		if err := u.storage.ReplaceYear(year, h); err != nil {
+			u.logger.Errorf("storage.ReplaceYear(%d) error: %s", year, err)
+		}
 	}
+}
 
-	if err := u.storage.Set(h); err != nil {
-		u.logger.Errorf("storage.Set error: %s", err)
-		return
+// yearsToUpdate returns years to scrape at the moment. The next year is
+// scraped since november, the current year is still scraped then, so it's
+// loaded after restarts without backups.
+func yearsToUpdate(now time.Time) []int {
+	year := now.Year()
+	if now.Month() >= time.November {
+		return []int{year, year + 1}
 	}
+	return []int{year}
 }
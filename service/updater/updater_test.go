@@ -0,0 +1,84 @@
+package updater
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/logger"
+	"github.com/mwf/golidays/service/store/memory"
+)
+
+type staticCrawler model.Holidays
+
+func (c staticCrawler) ScrapeYear(year int) (model.Holidays, error) {
+	holidays := model.Holidays{}
+	for _, h := range c {
+		if h.Date.Year == year {
+			holidays = append(holidays, h)
+		}
+	}
+	return holidays, nil
+}
+
+func TestPerform_replaceYear(t *testing.T) {
+	year := 2019
+
+	storage := memory.New()
+	err := storage.Set(model.Holidays{
+		{Date: model.NewDate(year-1, 12, 31), Type: model.TypeHoliday},
+		{Date: model.NewDate(year, 5, 1), Type: model.TypeHoliday},
+		{Date: model.NewDate(year, 5, 4), Type: model.TypeTransferred},
+	})
+	if err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	crawled := model.Holidays{
+		{Date: model.NewDate(year, 5, 1), Type: model.TypeHoliday},
+		{Date: model.NewDate(year, 5, 9), Type: model.TypeHoliday},
+	}
+	u, err := New(model.CalendarRU, storage, staticCrawler(crawled), time.Hour, &logger.NilLogger{})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	u.now = func() time.Time { return time.Date(year, time.May, 15, 12, 0, 0, 0, time.UTC) }
+	u.perform()
+
+	dump := storage.Dump()
+	sort.Sort(model.HolidaysByDate(dump))
+	expected := append(model.Holidays{{Date: model.NewDate(year-1, 12, 31), Type: model.TypeHoliday}}, crawled...)
+	if !reflect.DeepEqual(dump, expected) {
+		t.Errorf("stored data %#v != expected %#v", dump, expected)
+	}
+}
+
+func TestPerform_november(t *testing.T) {
+	crawled := model.Holidays{
+		{Date: model.NewDate(2019, 12, 31), Type: model.TypePreholiday},
+		{Date: model.NewDate(2020, 1, 1), Type: model.TypeHoliday},
+	}
+
+	// empty storage, e.g. memory.Store after restart without backups
+	storage := memory.New()
+	u, err := New(model.CalendarRU, storage, staticCrawler(crawled), time.Hour, &logger.NilLogger{})
+	if err != nil {
+		t.Fatalf("New failed: %s", err)
+	}
+	u.now = func() time.Time { return time.Date(2019, time.November, 15, 12, 0, 0, 0, time.UTC) }
+	u.perform()
+
+	if years, expected := storage.Years(), []int{2019, 2020}; !reflect.DeepEqual(years, expected) {
+		t.Errorf("loaded years %#v != expected %#v", years, expected)
+	}
+
+	h, ok, err := storage.Get(model.NewDate(2019, 12, 31))
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if !ok || h != crawled[0] {
+		t.Errorf("current year holiday %#v is not loaded", crawled[0])
+	}
+}
@@ -0,0 +1,71 @@
+package workday
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mwf/golidays/model"
+)
+
+// Weekly working hours regimes, see model.Week40
+const (
+	Week40 = model.Week40
+	Week39 = model.Week39
+	Week36 = model.Week36
+	Week24 = model.Week24
+)
+
+// Norm is a working time norm for a period of days, see model.Norm
+type Norm = model.Norm
+
+// YearNorms is a year norms breakdown, like in the official summary table
+type YearNorms struct {
+	Months   [12]Norm `json:"months" yaml:"months"`
+	Quarters [4]Norm  `json:"quarters" yaml:"quarters"`
+	Halves   [2]Norm  `json:"halves" yaml:"halves"`
+	Year     Norm     `json:"year" yaml:"year"`
+}
+
+func (c *calculator) Norm(from, to model.Date) (Norm, error) {
+	if to.Before(from) {
+		return Norm{}, fmt.Errorf("invalid range: %s > %s", from, to)
+	}
+
+	holidays, err := c.getter.GetRange(from, to)
+	if err != nil {
+		return Norm{}, err
+	}
+
+	return NormOf(from, to, holidays), nil
+}
+
+func (c *calculator) YearNorms(year int) (YearNorms, error) {
+	holidays, err := c.getter.GetRange(model.NewDate(year, time.January, 1), model.NewDate(year, time.December, 31))
+	if err != nil {
+		return YearNorms{}, err
+	}
+
+	norms := YearNorms{}
+	for i := range norms.Months {
+		from := model.NewDate(year, time.Month(i+1), 1)
+		to := from.AddDate(0, 1, -1)
+		norms.Months[i] = NormOf(from, to, holidays)
+	}
+
+	for i := range norms.Quarters {
+		m := norms.Months[i*3 : i*3+3]
+		norms.Quarters[i] = m[0].Add(m[1]).Add(m[2])
+	}
+	for i := range norms.Halves {
+		norms.Halves[i] = norms.Quarters[i*2].Add(norms.Quarters[i*2+1])
+	}
+	norms.Year = norms.Halves[0].Add(norms.Halves[1])
+
+	return norms, nil
+}
+
+// NormOf calculates the norm for the period between 'from' and 'to' dates
+// inclusively, see model.NormOf
+func NormOf(from, to model.Date, holidays model.Holidays) Norm {
+	return model.NormOf(from, to, holidays)
+}
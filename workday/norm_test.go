@@ -0,0 +1,63 @@
+package workday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+)
+
+func TestNorm(t *testing.T) {
+	c := newCalculator(t)
+
+	n, err := c.Norm(model.NewDate(2019, 5, 1), model.NewDate(2019, 5, 31))
+	if err != nil {
+		t.Fatalf("Norm failed: %s", err)
+	}
+
+	expected := Norm{
+		From:           model.NewDate(2019, 5, 1),
+		To:             model.NewDate(2019, 5, 31),
+		CalendarDays:   31,
+		WorkingDays:    18,
+		DaysOff:        13,
+		PreholidayDays: 1,
+	}
+	if n != expected {
+		t.Errorf("norm %#v != expected %#v", n, expected)
+	}
+	if hours := n.Hours(Week40); hours != 143*time.Hour {
+		t.Errorf("40-hours week norm %s != 143h", hours)
+	}
+}
+
+func TestYearNorms(t *testing.T) {
+	c := newCalculator(t)
+
+	norms, err := c.YearNorms(2019)
+	if err != nil {
+		t.Fatalf("YearNorms failed: %s", err)
+	}
+
+	if norms.Months[4].WorkingDays != 18 {
+		t.Errorf("May working days %d != 18", norms.Months[4].WorkingDays)
+	}
+	if norms.Months[3].PreholidayDays != 1 || norms.Months[3].DaysOff != 2 {
+		t.Errorf("unexpected April norm %#v", norms.Months[3])
+	}
+
+	q2 := norms.Quarters[1]
+	if q2.CalendarDays != 91 || q2.DaysOff != 15 || q2.PreholidayDays != 2 {
+		t.Errorf("unexpected second quarter norm %#v", q2)
+	}
+	if q2.From != model.NewDate(2019, 4, 1) || q2.To != model.NewDate(2019, 6, 30) {
+		t.Errorf("unexpected second quarter bounds %s - %s", q2.From, q2.To)
+	}
+
+	if norms.Year.CalendarDays != 365 || norms.Year.DaysOff != 15 {
+		t.Errorf("unexpected year norm %#v", norms.Year)
+	}
+	if norms.Halves[0].To != model.NewDate(2019, 6, 30) || norms.Halves[1].CalendarDays != 184 {
+		t.Errorf("unexpected half-year norms %#v", norms.Halves)
+	}
+}
@@ -0,0 +1,39 @@
+package workday
+
+import (
+	"time"
+
+	"github.com/mwf/golidays/model"
+)
+
+func (c *calculator) IsWorkdayTime(t time.Time, loc *time.Location) (bool, error) {
+	return c.IsWorkday(model.DateIn(t, loc))
+}
+
+func (c *calculator) AddWorkdaysTime(t time.Time, n int, loc *time.Location) (time.Time, error) {
+	date, err := c.AddWorkdays(model.DateIn(t, loc), n)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.In(loc), nil
+}
+
+func (c *calculator) WorkdaysBetweenTime(from, to time.Time, loc *time.Location) (int, error) {
+	return c.WorkdaysBetween(model.DateIn(from, loc), model.DateIn(to, loc))
+}
+
+func (c *calculator) NextWorkdayTime(t time.Time, loc *time.Location) (time.Time, error) {
+	date, err := c.NextWorkday(model.DateIn(t, loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.In(loc), nil
+}
+
+func (c *calculator) PrevWorkdayTime(t time.Time, loc *time.Location) (time.Time, error) {
+	date, err := c.PrevWorkday(model.DateIn(t, loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.In(loc), nil
+}
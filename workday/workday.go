@@ -0,0 +1,165 @@
+package workday
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+)
+
+const (
+	// window is a number of days fetched from storage at once while walking
+	// over the calendar
+	window = 32
+	// maxDaysOff is a limit of consecutive days off, there are no such long
+	// holidays in real calendars, so it's definitely broken data
+	maxDaysOff = 366
+)
+
+// Calculator performs working days arithmetic
+type Calculator interface {
+	// IsWorkday reports if the date is a working day
+	IsWorkday(date model.Date) (bool, error)
+	// AddWorkdays returns the date shifted by n working days. Negative n shifts
+	// the date backwards, zero n returns the date itself.
+	AddWorkdays(date model.Date, n int) (model.Date, error)
+	// WorkdaysBetween returns number of working days between 'from' and 'to'
+	// dates inclusively
+	WorkdaysBetween(from, to model.Date) (int, error)
+	// NextWorkday returns the first working day after the date
+	NextWorkday(date model.Date) (model.Date, error)
+	// PrevWorkday returns the last working day before the date
+	PrevWorkday(date model.Date) (model.Date, error)
+	// Norm returns working time norm for the period between 'from' and 'to'
+	// dates inclusively
+	Norm(from, to model.Date) (Norm, error)
+	// YearNorms returns working time norms for the year with monthly,
+	// quarterly and half-year breakdowns
+	YearNorms(year int) (YearNorms, error)
+
+	// Time wrappers take dates of times in the location, so the same instant
+	// may be different days in different locations. Returned times are
+	// midnights of the dates in the location.
+	IsWorkdayTime(t time.Time, loc *time.Location) (bool, error)
+	AddWorkdaysTime(t time.Time, n int, loc *time.Location) (time.Time, error)
+	WorkdaysBetweenTime(from, to time.Time, loc *time.Location) (int, error)
+	NextWorkdayTime(t time.Time, loc *time.Location) (time.Time, error)
+	PrevWorkdayTime(t time.Time, loc *time.Location) (time.Time, error)
+}
+
+// calculator is a Calculator implementation on top of holidays getter
+type calculator struct {
+	getter store.HolidayGetter
+}
+
+var _ Calculator = &calculator{}
+
+// New returns Calculator, using getter as a source of holidays
+func New(getter store.HolidayGetter) Calculator {
+	return &calculator{
+		getter: getter,
+	}
+}
+
+// IsDayOff reports if the holiday is a non-working day, see model.IsDayOff
+func IsDayOff(h model.Holiday) bool {
+	return model.IsDayOff(h)
+}
+
+func (c *calculator) IsWorkday(date model.Date) (bool, error) {
+	h, ok, err := c.getter.Get(date)
+	if err != nil {
+		return false, err
+	}
+
+	return !ok || !IsDayOff(h), nil
+}
+
+func (c *calculator) AddWorkdays(date model.Date, n int) (model.Date, error) {
+	if n == 0 {
+		return date, nil
+	}
+
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+
+	var result model.Date
+	err := c.walk(date.AddDays(step), step, func(d model.Date, workday bool) bool {
+		if workday {
+			n--
+		}
+		if n == 0 {
+			result = d
+			return false
+		}
+		return true
+	})
+
+	return result, err
+}
+
+func (c *calculator) WorkdaysBetween(from, to model.Date) (int, error) {
+	norm, err := c.Norm(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return norm.WorkingDays, nil
+}
+
+func (c *calculator) NextWorkday(date model.Date) (model.Date, error) {
+	return c.AddWorkdays(date, 1)
+}
+
+func (c *calculator) PrevWorkday(date model.Date) (model.Date, error) {
+	return c.AddWorkdays(date, -1)
+}
+
+// walk iterates over days starting from 'day' in direction of 'step' and calls
+// fn for every day until it returns false. Holidays are fetched from getter
+// by windows to avoid a storage request per day. Windows do not cross years
+// bounds, so the walk fails only on entering a year, which is not loaded.
+func (c *calculator) walk(day model.Date, step int, fn func(day model.Date, workday bool) bool) error {
+	daysOff := 0
+	for {
+		from, to := day, day.AddDays(step*(window-1))
+		if step < 0 {
+			from, to = to, from
+		}
+		if from.Year != day.Year {
+			from = model.NewDate(day.Year, time.January, 1)
+		}
+		if to.Year != day.Year {
+			to = model.NewDate(day.Year, time.December, 31)
+		}
+		days := to.DaysSince(from) + 1
+
+		holidays, err := c.getter.GetRange(from, to)
+		if err != nil {
+			return err
+		}
+		off := make(map[model.Date]bool, len(holidays))
+		for _, h := range holidays {
+			off[h.Date] = IsDayOff(h)
+		}
+
+		for i := 0; i < days; i++ {
+			if off[day] {
+				daysOff++
+				if daysOff > maxDaysOff {
+					return fmt.Errorf("more than %d days off in a row till %s", maxDaysOff, day)
+				}
+			} else {
+				daysOff = 0
+			}
+
+			if !fn(day, !off[day]) {
+				return nil
+			}
+			day = day.AddDays(step)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package workday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mwf/golidays/model"
+	"github.com/mwf/golidays/service/store"
+	"github.com/mwf/golidays/service/store/memory"
+)
+
+// newCalculator returns calculator on top of May 2019 russian calendar
+func newCalculator(t *testing.T) Calculator {
+	holidays := model.Holidays{
+		{Date: model.NewDate(2019, 4, 27), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 4, 28), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 4, 30), Type: model.TypePreholiday},
+		{Date: model.NewDate(2019, 5, 1), Type: model.TypeHoliday},
+		{Date: model.NewDate(2019, 5, 2), Type: model.TypeTransferred},
+		{Date: model.NewDate(2019, 5, 3), Type: model.TypeTransferred},
+		{Date: model.NewDate(2019, 5, 4), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 5), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 8), Type: model.TypePreholiday},
+		{Date: model.NewDate(2019, 5, 9), Type: model.TypeHoliday},
+		{Date: model.NewDate(2019, 5, 10), Type: model.TypeTransferred},
+		{Date: model.NewDate(2019, 5, 11), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 12), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 18), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 19), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 25), Type: model.TypeWeekend},
+		{Date: model.NewDate(2019, 5, 26), Type: model.TypeWeekend},
+	}
+
+	s := memory.New()
+	if err := s.Set(holidays); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	return New(s)
+}
+
+func TestIsWorkday(t *testing.T) {
+	c := newCalculator(t)
+
+	cases := map[model.Date]bool{
+		model.NewDate(2019, 4, 29): true,
+		model.NewDate(2019, 4, 30): true, // preholiday
+		model.NewDate(2019, 5, 1):  false,
+		model.NewDate(2019, 5, 4):  false,
+		model.NewDate(2019, 5, 6):  true,
+		// 2019-05-09 01:30 in Moscow
+		model.DateIn(time.Date(2019, 5, 8, 22, 30, 0, 0, time.UTC), time.FixedZone("MSK", 3*60*60)): false,
+	}
+
+	for date, expected := range cases {
+		workday, err := c.IsWorkday(date)
+		if err != nil {
+			t.Fatalf("IsWorkday failed: %s", err)
+		}
+		if workday != expected {
+			t.Errorf("IsWorkday(%s) = %t, expected %t", date, workday, expected)
+		}
+	}
+}
+
+func TestAddWorkdays(t *testing.T) {
+	c := newCalculator(t)
+
+	cases := []struct {
+		date     model.Date
+		n        int
+		expected model.Date
+	}{
+		{model.NewDate(2019, 4, 29), 0, model.NewDate(2019, 4, 29)},
+		{model.NewDate(2019, 5, 1), 0, model.NewDate(2019, 5, 1)},
+		{model.NewDate(2019, 4, 29), 1, model.NewDate(2019, 4, 30)},
+		{model.NewDate(2019, 4, 29), 2, model.NewDate(2019, 5, 6)},
+		{model.NewDate(2019, 4, 30), 5, model.NewDate(2019, 5, 14)},
+		{model.NewDate(2019, 5, 13), -1, model.NewDate(2019, 5, 8)},
+		{model.NewDate(2019, 5, 13), -4, model.NewDate(2019, 4, 30)},
+		{model.NewDate(2019, 4, 1), 40, model.NewDate(2019, 5, 24)},
+		{model.NewDate(2019, 5, 24), -40, model.NewDate(2019, 4, 1)},
+	}
+
+	for _, tc := range cases {
+		date, err := c.AddWorkdays(tc.date, tc.n)
+		if err != nil {
+			t.Fatalf("AddWorkdays failed: %s", err)
+		}
+		if date != tc.expected {
+			t.Errorf("AddWorkdays(%s, %d) = %s, expected %s", tc.date, tc.n, date, tc.expected)
+		}
+	}
+}
+
+func TestNextPrevWorkday(t *testing.T) {
+	c := newCalculator(t)
+
+	next, err := c.NextWorkday(model.NewDate(2019, 4, 30))
+	if err != nil {
+		t.Fatalf("NextWorkday failed: %s", err)
+	}
+	if expected := model.NewDate(2019, 5, 6); next != expected {
+		t.Errorf("next workday %s != expected %s", next, expected)
+	}
+
+	prev, err := c.PrevWorkday(model.NewDate(2019, 5, 6))
+	if err != nil {
+		t.Fatalf("PrevWorkday failed: %s", err)
+	}
+	if expected := model.NewDate(2019, 4, 30); prev != expected {
+		t.Errorf("prev workday %s != expected %s", prev, expected)
+	}
+}
+
+func TestWorkdaysBetween(t *testing.T) {
+	c := newCalculator(t)
+
+	n, err := c.WorkdaysBetween(model.NewDate(2019, 5, 1), model.NewDate(2019, 5, 31))
+	if err != nil {
+		t.Fatalf("WorkdaysBetween failed: %s", err)
+	}
+	if n != 18 {
+		t.Errorf("workdays in May 2019: %d != 18", n)
+	}
+
+	n, err = c.WorkdaysBetween(model.NewDate(2019, 5, 6), model.NewDate(2019, 5, 6))
+	if err != nil {
+		t.Fatalf("WorkdaysBetween failed: %s", err)
+	}
+	if n != 1 {
+		t.Errorf("workdays in a single working day: %d != 1", n)
+	}
+
+	_, err = c.WorkdaysBetween(model.NewDate(2019, 5, 31), model.NewDate(2019, 5, 1))
+	if err == nil {
+		t.Fatalf("Error should not be empty")
+	}
+}
+
+func TestNextWorkday_yearNotLoaded(t *testing.T) {
+	c := newCalculator(t)
+
+	// the window of days is limited by the year end
+	next, err := c.NextWorkday(model.NewDate(2019, 12, 30))
+	if err != nil {
+		t.Fatalf("NextWorkday failed: %s", err)
+	}
+	if expected := model.NewDate(2019, 12, 31); next != expected {
+		t.Errorf("next workday %s != expected %s", next, expected)
+	}
+
+	_, err = c.NextWorkday(model.NewDate(2019, 12, 31))
+	if !store.IsYearNotLoaded(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTimeWrappers(t *testing.T) {
+	c := newCalculator(t)
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	// 2019-04-30 in UTC, but 2019-05-01 in Moscow
+	instant := time.Date(2019, 4, 30, 22, 30, 0, 0, time.UTC)
+
+	workday, err := c.IsWorkdayTime(instant, time.UTC)
+	if err != nil {
+		t.Fatalf("IsWorkdayTime failed: %s", err)
+	}
+	if !workday {
+		t.Errorf("2019-04-30 should be a working day in UTC")
+	}
+	workday, err = c.IsWorkdayTime(instant, msk)
+	if err != nil {
+		t.Fatalf("IsWorkdayTime failed: %s", err)
+	}
+	if workday {
+		t.Errorf("2019-05-01 should be a day off in Moscow")
+	}
+
+	next, err := c.NextWorkdayTime(instant, msk)
+	if err != nil {
+		t.Fatalf("NextWorkdayTime failed: %s", err)
+	}
+	if expected := time.Date(2019, 5, 6, 0, 0, 0, 0, msk); !next.Equal(expected) || next.Location() != msk {
+		t.Errorf("next workday %s != expected %s", next, expected)
+	}
+
+	prev, err := c.PrevWorkdayTime(instant, msk)
+	if err != nil {
+		t.Fatalf("PrevWorkdayTime failed: %s", err)
+	}
+	if expected := time.Date(2019, 4, 30, 0, 0, 0, 0, msk); !prev.Equal(expected) {
+		t.Errorf("prev workday %s != expected %s", prev, expected)
+	}
+
+	added, err := c.AddWorkdaysTime(instant, 1, time.UTC)
+	if err != nil {
+		t.Fatalf("AddWorkdaysTime failed: %s", err)
+	}
+	if expected := time.Date(2019, 5, 6, 0, 0, 0, 0, time.UTC); !added.Equal(expected) {
+		t.Errorf("added workday %s != expected %s", added, expected)
+	}
+
+	n, err := c.WorkdaysBetweenTime(instant, time.Date(2019, 5, 6, 12, 0, 0, 0, time.UTC), msk)
+	if err != nil {
+		t.Fatalf("WorkdaysBetweenTime failed: %s", err)
+	}
+	if n != 1 {
+		t.Errorf("workdays between: %d != 1", n)
+	}
+}